@@ -0,0 +1,41 @@
+package logparams
+
+import "strings"
+
+// allowed checks if the parameter at path may be logged in allowlist mode. A path is
+// a form or query key, or the dot separated keys of a JSON value, e.g. "user.email".
+// Array elements share the path of their array. Allowing a path allows everything
+// below it.
+func (lp *requestParams) allowed(path string) bool {
+	if !lp.Allowlist {
+		return true
+	}
+
+	for _, key := range lp.AllowKeys {
+		if matchPath(key, path) {
+			return true
+		}
+	}
+
+	for _, key := range lp.structRules().allow {
+		if matchPath(key, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPath checks if path is allowedPath or below it.
+func matchPath(allowedPath string, path string) bool {
+	return allowedPath == path || strings.HasPrefix(path, allowedPath+".")
+}
+
+// jsonPath returns the path of key in the object at parent.
+func jsonPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+
+	return parent + "." + key
+}
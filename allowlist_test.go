@@ -0,0 +1,104 @@
+package logparams
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Allowlist
+
+func TestAllowlistJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"card\" => \"[FILTERED]\", \"user\" => {\"id\" => \"42\", \"name\" => \"[FILTERED]\"}}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Allowlist: true, AllowKeys: []string{"user.id"}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"card":"4242424242424242","user":{"id":"42","name":"baz"}}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestAllowlistJSONBodyToField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Allowlist: true, AllowKeys: []string{"amount", "items.name"}}
+		fields := lp.ToFields()
+		if fields.Json["amount"] != float64(100) {
+			t.Errorf("Expected value was incorrect, got %v, want: %v", fields.Json["amount"], 100)
+		}
+		if fields.Json["count"] != "[FILTERED]" {
+			t.Errorf("Expected value was incorrect, got %v, want: %s", fields.Json["count"], "[FILTERED]")
+		}
+
+		item := fields.Json["items"].([]interface{})[0].(map[string]interface{})
+		if item["name"] != "foo" || item["note"] != "[FILTERED]" {
+			t.Errorf("Expected item was incorrect, got %v", item)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"amount":100,"count":3,"items":[{"name":"foo","note":"bar"}]}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestAllowlistRemoveFormToLogger(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"foo\"}"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Allowlist: true, AllowKeys: []string{"name"}, AllowlistRedaction: Remove}
+		lp.ToLogger(&logger)
+		result := strings.TrimSuffix(str.String(), "\n")
+		if result != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL, "POST", "name=foo&ssn=123-45-6789", t)
+}
+
+func TestAllowlistWithoutKeysQueryToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"[FILTERED]\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Allowlist: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?foo=bar")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
@@ -0,0 +1,174 @@
+package logparams
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is the queue size of an AsyncSink when QueueSize is not set.
+const DefaultQueueSize = 1024
+
+// ErrDropped is returned by an AsyncSink when an entry is dropped because its
+// queue is full or it is closed.
+var ErrDropped = errors.New("logparams: entry dropped")
+
+// Overflow is what an AsyncSink does with an entry when its queue is full.
+type Overflow int
+
+const (
+	// DropWhenFull drops the entry and counts it in Dropped, so requests never wait
+	// on the log destination.
+	DropWhenFull Overflow = iota
+	// BlockWhenFull waits for room in the queue, so no entry is lost.
+	BlockWhenFull
+)
+
+// AsyncConfig configures an AsyncSink.
+// QueueSize is the number of entries buffered for the workers (default 1024).
+// Workers is the number of goroutines writing entries (default 1). With more than
+// one worker, entries may be written out of order.
+// Overflow is what happens when the queue is full (default DropWhenFull).
+type AsyncConfig struct {
+	QueueSize int
+	Workers   int
+	Overflow  Overflow
+}
+
+// AsyncSink sends entries to a Sink from a bounded queue on worker goroutines, so
+// a slow log destination does not add latency to requests. Close it on shutdown to
+// write the entries left in the queue.
+type AsyncSink struct {
+	sink     Sink
+	overflow Overflow
+	queue    chan Entry
+	workers  sync.WaitGroup
+
+	// closeMu guards sending to the queue against closing it.
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pending int
+
+	logged  uint64
+	dropped uint64
+	failed  uint64
+}
+
+// NewAsyncSink returns an AsyncSink sending entries to sink, with its workers started.
+func NewAsyncSink(sink Sink, config AsyncConfig) *AsyncSink {
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	s := &AsyncSink{
+		sink:     sink,
+		overflow: config.Overflow,
+		queue:    make(chan Entry, queueSize),
+	}
+	s.idle = sync.NewCond(&s.mu)
+
+	s.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+
+	return s
+}
+
+// Log queues the entry to be sent. It returns ErrDropped if the entry was dropped
+// because the queue is full or the sink is closed. Errors from the Sink are counted
+// in Failed.
+func (s *AsyncSink) Log(entry Entry) error {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		atomic.AddUint64(&s.dropped, 1)
+		return ErrDropped
+	}
+
+	s.addPending(1)
+	if s.overflow == BlockWhenFull {
+		s.queue <- entry
+		return nil
+	}
+
+	select {
+	case s.queue <- entry:
+		return nil
+	default:
+		s.addPending(-1)
+		atomic.AddUint64(&s.dropped, 1)
+		return ErrDropped
+	}
+}
+
+// Flush waits until every queued entry has been written.
+func (s *AsyncSink) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.pending > 0 {
+		s.idle.Wait()
+	}
+}
+
+// Close writes the queued entries and stops the workers. Entries logged after
+// Close are dropped. The Sink is not closed.
+func (s *AsyncSink) Close() {
+	s.closeMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.closeMu.Unlock()
+
+	s.workers.Wait()
+}
+
+// Logged returns the number of entries sent.
+func (s *AsyncSink) Logged() uint64 {
+	return atomic.LoadUint64(&s.logged)
+}
+
+// Dropped returns the number of entries dropped because the queue was full or the
+// sink was closed.
+func (s *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Failed returns the number of entries the Sink returned an error for.
+func (s *AsyncSink) Failed() uint64 {
+	return atomic.LoadUint64(&s.failed)
+}
+
+// work sends entries from the queue until it is closed.
+func (s *AsyncSink) work() {
+	defer s.workers.Done()
+	for entry := range s.queue {
+		if err := s.sink.Log(entry); err != nil {
+			atomic.AddUint64(&s.failed, 1)
+		} else {
+			atomic.AddUint64(&s.logged, 1)
+		}
+		s.addPending(-1)
+	}
+}
+
+// addPending adds delta to the number of queued entries, waking Flush once it
+// reaches zero.
+func (s *AsyncSink) addPending(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending += delta
+	if s.pending == 0 {
+		s.idle.Broadcast()
+	}
+}
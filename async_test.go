@@ -0,0 +1,140 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Async sink
+
+// blockingWriter blocks each write until release is closed, signalling started
+// when the first write begins.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// testEntry returns an entry with the parameters string.
+func testEntry(str string) Entry {
+	return Entry{Result: Result{String: str}}
+}
+
+func TestAsyncSinkToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}\nParameters: {\"foo\" => \"baz\"}\n"
+
+	var str bytes.Buffer
+	sink := NewAsyncSink(NewWriterSink(&str), AsyncConfig{})
+	defer sink.Close()
+
+	for _, query := range []string{"/?foo=bar", "/", "/?foo=baz"} {
+		lp := LogParams{Request: httptest.NewRequest("GET", query, nil)}
+		if err := lp.ToSink(sink); err != nil {
+			t.Errorf("Expected entry for %s to be queued", query)
+		}
+	}
+	sink.Flush()
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+	if sink.Logged() != 2 {
+		t.Errorf("Expected 2 logged entries, got %d", sink.Logged())
+	}
+}
+
+func TestAsyncSinkDropsWhenFull(t *testing.T) {
+	w := newBlockingWriter()
+	sink := NewAsyncSink(NewWriterSink(w), AsyncConfig{QueueSize: 1})
+
+	sink.Log(testEntry("first"))
+	<-w.started
+	if err := sink.Log(testEntry("second")); err != nil {
+		t.Errorf("Expected second entry to be queued")
+	}
+	if err := sink.Log(testEntry("third")); err != ErrDropped {
+		t.Errorf("Expected third entry to be dropped")
+	}
+
+	close(w.release)
+	sink.Close()
+
+	if w.String() != "first\nsecond\n" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", w.String(), "first\nsecond\n")
+	}
+	if sink.Logged() != 2 || sink.Dropped() != 1 {
+		t.Errorf("Expected 2 logged and 1 dropped entries, got %d and %d", sink.Logged(), sink.Dropped())
+	}
+}
+
+func TestAsyncSinkBlocksWhenFull(t *testing.T) {
+	w := newBlockingWriter()
+	sink := NewAsyncSink(NewWriterSink(w), AsyncConfig{QueueSize: 1, Overflow: BlockWhenFull})
+
+	sink.Log(testEntry("first"))
+	<-w.started
+	sink.Log(testEntry("second"))
+
+	done := make(chan error)
+	go func() {
+		done <- sink.Log(testEntry("third"))
+	}()
+
+	close(w.release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected third entry to be queued")
+	}
+	sink.Close()
+
+	if w.String() != "first\nsecond\nthird\n" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", w.String(), "first\nsecond\nthird\n")
+	}
+	if sink.Dropped() != 0 {
+		t.Errorf("Expected no dropped entries, got %d", sink.Dropped())
+	}
+}
+
+func TestAsyncSinkClose(t *testing.T) {
+	var str bytes.Buffer
+	sink := NewAsyncSink(NewWriterSink(&str), AsyncConfig{Workers: 4})
+
+	for i := 0; i < 100; i++ {
+		sink.Log(testEntry("entry"))
+	}
+	sink.Close()
+	sink.Close()
+
+	if strings.Count(str.String(), "entry\n") != 100 {
+		t.Errorf("Expected 100 entries written on close, got %d", strings.Count(str.String(), "entry\n"))
+	}
+	if err := sink.Log(testEntry("late")); err != ErrDropped {
+		t.Errorf("Expected entry logged after close to be dropped")
+	}
+	if sink.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d", sink.Dropped())
+	}
+}
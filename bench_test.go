@@ -0,0 +1,102 @@
+package logparams
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Benchmarks
+
+const benchJSONBody = `{"user":{"name":"Jane","email":"jane@example.com","password":"hunter2","roles":["admin","dev"]},"page":2,"active":true,"tags":[{"id":1,"name":"a"},{"id":2,"name":"b"}]}`
+
+func benchRequest(method string, target string, contentType string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Request-ID", "f9b8c3a2")
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "abc123"})
+
+	return req
+}
+
+func benchmarkToString(b *testing.B, lp LogParams, newRequest func() *http.Request) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lp.Request = newRequest()
+		_ = lp.ToString()
+	}
+}
+
+func BenchmarkToStringForm(b *testing.B) {
+	benchmarkToString(b, LogParams{}, func() *http.Request {
+		return benchRequest("POST", "/users", "application/x-www-form-urlencoded", "name=Jane&email=jane%40example.com&password=hunter2&page=2")
+	})
+}
+
+func BenchmarkToStringQuery(b *testing.B) {
+	benchmarkToString(b, LogParams{}, func() *http.Request {
+		return benchRequest("GET", "/users?name=Jane&email=jane%40example.com&password=hunter2&page=2", "", "")
+	})
+}
+
+func BenchmarkToStringJSON(b *testing.B) {
+	benchmarkToString(b, LogParams{}, func() *http.Request {
+		return benchRequest("POST", "/users", "application/json", benchJSONBody)
+	})
+}
+
+func BenchmarkToStringJSONWithOptions(b *testing.B) {
+	lp := LogParams{
+		LogHeaders:     []string{"X-Request-ID"},
+		ShowCookies:    true,
+		MaxValueLength: 64,
+		Redact:         []RedactRule{{Key: "email", Redaction: PartialMask}},
+		PathParams: func(r *http.Request) map[string]string {
+			return map[string]string{"id": "42"}
+		},
+	}
+	benchmarkToString(b, lp, func() *http.Request {
+		return benchRequest("POST", "/users/42", "application/json", benchJSONBody)
+	})
+}
+
+func BenchmarkMiddleware(b *testing.B) {
+	logger := log.New(ioutil.Discard, "", 0)
+	handler := Middleware(logger, LogParams{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), benchRequest("POST", "/users", "application/json", benchJSONBody))
+	}
+}
+
+func benchLargeJSONBody() string {
+	var users []string
+	for i := 0; i < 5000; i++ {
+		users = append(users, fmt.Sprintf(`{"id":%d,"name":"user %d","password":"hunter2"}`, i, i))
+	}
+
+	return fmt.Sprintf(`{"users":[%s]}`, strings.Join(users, ","))
+}
+
+func BenchmarkToStringLargeJSON(b *testing.B) {
+	body := benchLargeJSONBody()
+	benchmarkToString(b, LogParams{MaxArrayElements: 10}, func() *http.Request {
+		return benchRequest("POST", "/users", "application/json", body)
+	})
+}
+
+func BenchmarkToStringLargeJSONStream(b *testing.B) {
+	body := benchLargeJSONBody()
+	benchmarkToString(b, LogParams{MaxArrayElements: 10, StreamJSON: true}, func() *http.Request {
+		return benchRequest("POST", "/users", "application/json", body)
+	})
+}
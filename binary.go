@@ -0,0 +1,91 @@
+package logparams
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMinBinaryLength is the length a base64 or hex value must reach to be
+// summarized when MinBinaryLength is not set.
+const DefaultMinBinaryLength = 256
+
+// summarizeBinary will replace binary, base64 and hex values with a summary of their
+// size and hash, e.g. #<binary 4096 bytes sha256=3f9a0c1d2e4b5a69>.
+func (lp *requestParams) summarizeBinary(key string, value string) string {
+	summarize, ok := lp.BinaryKeys[key]
+	if ok && !summarize {
+		return value
+	}
+
+	if !ok && !lp.SummarizeBinary {
+		return value
+	}
+
+	if b, isBinary := lp.decodeBinary(value); isBinary || summarize {
+		if b == nil {
+			b = []byte(value)
+		}
+		sum := sha256.Sum256(b)
+		return fmt.Sprintf("#<binary %d bytes sha256=%s>", len(b), hex.EncodeToString(sum[:8]))
+	}
+
+	return value
+}
+
+// decodeBinary checks if the value is binary, or a long base64 or hex string, and
+// returns the bytes it holds.
+func (lp *requestParams) decodeBinary(value string) ([]byte, bool) {
+	if !isPrintable(value) {
+		return []byte(value), true
+	}
+
+	minLength := lp.MinBinaryLength
+	if minLength <= 0 {
+		minLength = DefaultMinBinaryLength
+	}
+
+	// Data URIs, e.g. data:image/png;base64,iVBORw0KGgo...
+	if strings.HasPrefix(value, "data:") {
+		if i := strings.Index(value, ";base64,"); i != -1 {
+			value = value[i+len(";base64,"):]
+		}
+	}
+
+	if len(value) < minLength {
+		return nil, false
+	}
+
+	if b, err := hex.DecodeString(value); err == nil {
+		return b, true
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := encoding.DecodeString(value); err == nil {
+			return b, true
+		}
+	}
+
+	return nil, false
+}
+
+// isPrintable checks if the value is valid UTF-8 without control characters other
+// than whitespace. A replacement character is taken as a sign of invalid bytes that
+// were already replaced, e.g. by encoding/json.
+func isPrintable(value string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+
+	for _, r := range value {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,97 @@
+package logparams
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Binary values
+
+func TestBase64JSONBodyToString(t *testing.T) {
+	image := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xff, 0x00, 0x10}, 100))
+	expectedResults := "Parameters: {\"image\" => \"#<binary 300 bytes sha256=6b132bb18e68729b>\", \"name\" => \"foo\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, SummarizeBinary: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Json["image"] != "#<binary 300 bytes sha256=6b132bb18e68729b>" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Json["image"], "#<binary 300 bytes sha256=6b132bb18e68729b>")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(fmt.Sprintf(`{"name":"foo","image":"data:image/png;base64,%s"}`, image))
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestBinaryFormToString(t *testing.T) {
+	expectedResults := "Parameters: {\"file\" => \"#<binary 5 bytes sha256=c038ae3bc0bc351d>\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, SummarizeBinary: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	params := url.Values{}
+	params.Set("file", "\x00\x01abc")
+
+	_, err := http.PostForm(server.URL, params)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestBinaryKeysToString(t *testing.T) {
+	expectedResults := "Parameters: {\"raw\" => \"\\x01\", \"token\" => \"#<binary 5 bytes sha256=f9b0078b5df596d2>\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, SummarizeBinary: true, BinaryKeys: map[string]bool{"raw": false, "token": true}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %q, want: %q", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?raw=%01&token=short")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestShortBase64IsNotSummarized(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"Zm9vYmFy\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, SummarizeBinary: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?name=Zm9vYmFy")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
@@ -0,0 +1,82 @@
+package logparams
+
+import (
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// invalidUTF8Replacement replaces invalid UTF-8 sequences in logged values.
+const invalidUTF8Replacement = "�"
+
+// charset returns the charset parameter of the request content type.
+func (lp *requestParams) charset() string {
+	_, params, err := mime.ParseMediaType(lp.Request.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	return params["charset"]
+}
+
+// bodyDecoder returns the decoder for the request charset, or nil if the body
+// should be treated as UTF-8.
+func (lp *requestParams) bodyDecoder() *encoding.Decoder {
+	charset := strings.ToLower(strings.TrimSpace(lp.charset()))
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return nil
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil
+	}
+
+	return enc.NewDecoder()
+}
+
+// decodeString will transcode s to UTF-8 with the decoder, and replace any
+// invalid UTF-8 sequences left over.
+func decodeString(decoder *encoding.Decoder, s string) string {
+	return toValidUTF8(transcodeString(decoder, s))
+}
+
+// transcodeString will transcode s to UTF-8 with the decoder.
+func transcodeString(decoder *encoding.Decoder, s string) string {
+	if decoder == nil {
+		return s
+	}
+
+	decoded, err := decoder.String(s)
+	if err != nil {
+		return s
+	}
+
+	return decoded
+}
+
+// decodeBytes will transcode b to UTF-8 with the decoder.
+func decodeBytes(decoder *encoding.Decoder, b []byte) []byte {
+	if decoder == nil {
+		return b
+	}
+
+	decoded, err := decoder.Bytes(b)
+	if err != nil {
+		return b
+	}
+
+	return decoded
+}
+
+// toValidUTF8 replaces each run of invalid UTF-8 bytes in s with U+FFFD.
+func toValidUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	return strings.ToValidUTF8(s, invalidUTF8Replacement)
+}
@@ -0,0 +1,82 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Charset decoding
+
+func TestShiftJISFormToString(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"日本\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Form["name"] != "日本" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Form["name"], "日本")
+		}
+	}))
+
+	defer server.Close()
+
+	value, _ := japanese.ShiftJIS.NewEncoder().String("日本")
+	body := "name=" + url.QueryEscape(value)
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=Shift_JIS")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestLatin1JSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"José\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte("{\"name\":\"Jos\xe9\"}")
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json; charset=ISO-8859-1")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestInvalidUTF8QueryParamsToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"b�r\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?foo=b%ff%fer")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
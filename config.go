@@ -0,0 +1,181 @@
+package logparams
+
+import (
+	"net/http"
+	"time"
+)
+
+// Config is the configuration of a Logger, built once by New and shared by every
+// request it logs, so it must not be changed once in use.
+// HideEmpty will not log or return "" if param is empty.
+// FilterPassword will filter password parameters (default true).
+// MaxDecompressedSize limits the size of a decompressed body (default 10MB).
+// AllowBodyMethods will only log the body for these HTTP methods (default all).
+// DenyBodyMethods will not log the body for these HTTP methods.
+// LogHeaders are the request headers to log alongside the parameters.
+// FilterHeaders are masked in addition to DefaultFilterHeaders.
+// ShowCookies will log the request cookies alongside the parameters.
+// LogCookies will only log these cookies when ShowCookies is set (default all).
+// FilterCookies are masked in addition to DefaultFilterCookies.
+// PathParams returns the router path parameters to log with the other parameters.
+// ShowResponseBody will log JSON response bodies in Middleware.
+// MaxResponseBodySize limits the size of a logged response body (default 64KB).
+// ShowLifecycle will log the Rails style Started and Processing lines in Middleware.
+// HandlerName returns the handler name for the Processing line.
+// Clock returns the current time for timestamps and durations (default time.Now).
+// MaxValueLength, MaxKeys, MaxDepth and MaxOutputSize limit the logged parameters (default unlimited).
+// SummarizeBinary will replace binary, base64 and hex values with a summary.
+// BinaryKeys will always (true) or never (false) summarize the values of these keys.
+// MinBinaryLength is the length a base64 or hex value must reach to be summarized (default 256).
+// Redact are the rules for redacting parameters, applied before the password filter.
+// HMACKey is the secret key for the HMAC redaction.
+// Allowlist will only log the form, query and JSON parameters in AllowKeys.
+// AllowKeys are the keys, or dot separated JSON paths, logged in allowlist mode.
+// AllowlistRedaction is how other parameters are logged in allowlist mode (default Mask).
+// BodyStruct is a struct value or pointer whose log tags define redaction rules, see StructRules.
+// Spec is an OpenAPI 3 or JSON Schema document whose sensitive parameters are redacted.
+// MaxArrayElements limits the logged elements of JSON arrays (default unlimited).
+// StreamJSON will render JSON bodies token by token instead of decoding them whole.
+// Sampler selects the requests whose parameters are logged in Middleware (default all).
+// Sink receives the parameters in Middleware and Transport instead of the logger.
+// Formatter formats the parameters in place of the Rails style string.
+type Config struct {
+	ShowEmpty           bool
+	ShowPassword        bool
+	HidePrefix          bool
+	MaxDecompressedSize int64
+	AllowBodyMethods    []string
+	DenyBodyMethods     []string
+	LogHeaders          []string
+	FilterHeaders       []string
+	ShowCookies         bool
+	LogCookies          []string
+	FilterCookies       []string
+	PathParams          PathParamsFunc
+	ShowResponseBody    bool
+	MaxResponseBodySize int
+	ShowLifecycle       bool
+	HandlerName         HandlerNameFunc
+	Clock               func() time.Time
+	MaxValueLength      int
+	MaxKeys             int
+	MaxDepth            int
+	MaxOutputSize       int
+	SummarizeBinary     bool
+	BinaryKeys          map[string]bool
+	MinBinaryLength     int
+	Redact              []RedactRule
+	HMACKey             []byte
+	Allowlist           bool
+	AllowKeys           []string
+	AllowlistRedaction  Redaction
+	BodyStruct          interface{}
+	Spec                *Spec
+	MaxArrayElements    int
+	StreamJSON          bool
+	Sampler             *Sampler
+	Sink                Sink
+	Formatter           Formatter
+}
+
+// requestParams is a request being logged with a Config.
+type requestParams struct {
+	*Config
+	Request   *http.Request
+	specRules []RedactRule
+}
+
+// params returns the request to log with the configuration.
+func (c *Config) params(r *http.Request) *requestParams {
+	return &requestParams{Config: c, Request: r, specRules: c.Spec.Rules(r)}
+}
+
+// logParams returns a LogParams for the request with the configuration.
+func (c *Config) logParams(r *http.Request) LogParams {
+	return LogParams{
+		Request:             r,
+		ShowEmpty:           c.ShowEmpty,
+		ShowPassword:        c.ShowPassword,
+		HidePrefix:          c.HidePrefix,
+		MaxDecompressedSize: c.MaxDecompressedSize,
+		AllowBodyMethods:    c.AllowBodyMethods,
+		DenyBodyMethods:     c.DenyBodyMethods,
+		LogHeaders:          c.LogHeaders,
+		FilterHeaders:       c.FilterHeaders,
+		ShowCookies:         c.ShowCookies,
+		LogCookies:          c.LogCookies,
+		FilterCookies:       c.FilterCookies,
+		PathParams:          c.PathParams,
+		ShowResponseBody:    c.ShowResponseBody,
+		MaxResponseBodySize: c.MaxResponseBodySize,
+		ShowLifecycle:       c.ShowLifecycle,
+		HandlerName:         c.HandlerName,
+		Clock:               c.Clock,
+		MaxValueLength:      c.MaxValueLength,
+		MaxKeys:             c.MaxKeys,
+		MaxDepth:            c.MaxDepth,
+		MaxOutputSize:       c.MaxOutputSize,
+		SummarizeBinary:     c.SummarizeBinary,
+		BinaryKeys:          c.BinaryKeys,
+		MinBinaryLength:     c.MinBinaryLength,
+		Redact:              c.Redact,
+		HMACKey:             c.HMACKey,
+		Allowlist:           c.Allowlist,
+		AllowKeys:           c.AllowKeys,
+		AllowlistRedaction:  c.AllowlistRedaction,
+		BodyStruct:          c.BodyStruct,
+		Spec:                c.Spec,
+		MaxArrayElements:    c.MaxArrayElements,
+		StreamJSON:          c.StreamJSON,
+		Sampler:             c.Sampler,
+		Sink:                c.Sink,
+		Formatter:           c.Formatter,
+	}
+}
+
+// config returns the configuration of lp.
+func (lp *LogParams) config() *Config {
+	return &Config{
+		ShowEmpty:           lp.ShowEmpty,
+		ShowPassword:        lp.ShowPassword,
+		HidePrefix:          lp.HidePrefix,
+		MaxDecompressedSize: lp.MaxDecompressedSize,
+		AllowBodyMethods:    lp.AllowBodyMethods,
+		DenyBodyMethods:     lp.DenyBodyMethods,
+		LogHeaders:          lp.LogHeaders,
+		FilterHeaders:       lp.FilterHeaders,
+		ShowCookies:         lp.ShowCookies,
+		LogCookies:          lp.LogCookies,
+		FilterCookies:       lp.FilterCookies,
+		PathParams:          lp.PathParams,
+		ShowResponseBody:    lp.ShowResponseBody,
+		MaxResponseBodySize: lp.MaxResponseBodySize,
+		ShowLifecycle:       lp.ShowLifecycle,
+		HandlerName:         lp.HandlerName,
+		Clock:               lp.Clock,
+		MaxValueLength:      lp.MaxValueLength,
+		MaxKeys:             lp.MaxKeys,
+		MaxDepth:            lp.MaxDepth,
+		MaxOutputSize:       lp.MaxOutputSize,
+		SummarizeBinary:     lp.SummarizeBinary,
+		BinaryKeys:          lp.BinaryKeys,
+		MinBinaryLength:     lp.MinBinaryLength,
+		Redact:              lp.Redact,
+		HMACKey:             lp.HMACKey,
+		Allowlist:           lp.Allowlist,
+		AllowKeys:           lp.AllowKeys,
+		AllowlistRedaction:  lp.AllowlistRedaction,
+		BodyStruct:          lp.BodyStruct,
+		Spec:                lp.Spec,
+		MaxArrayElements:    lp.MaxArrayElements,
+		StreamJSON:          lp.StreamJSON,
+		Sampler:             lp.Sampler,
+		Sink:                lp.Sink,
+		Formatter:           lp.Formatter,
+	}
+}
+
+// params returns the request of lp to log with its configuration.
+func (lp *LogParams) params() *requestParams {
+	return lp.config().params(lp.Request)
+}
@@ -0,0 +1,77 @@
+package logparams
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Config
+
+type testFormatter struct{}
+
+func (f *testFormatter) Format(result Result) string {
+	return result.String
+}
+
+func TestConfigMatchesLogParamsFields(t *testing.T) {
+	configType := reflect.TypeOf(Config{})
+	paramsType := reflect.TypeOf(LogParams{})
+	if paramsType.NumField() != configType.NumField()+1 {
+		t.Fatalf("Expected LogParams to have the fields of Config and Request, got %d and %d fields", paramsType.NumField(), configType.NumField())
+	}
+
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		paramsField, ok := paramsType.FieldByName(field.Name)
+		if !ok || paramsField.Type != field.Type {
+			t.Errorf("Expected LogParams to have field %s %s", field.Name, field.Type)
+		}
+	}
+}
+
+func TestLogParamsConfigRoundTrip(t *testing.T) {
+	var lp LogParams
+	v := reflect.ValueOf(&lp).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int, reflect.Int64:
+			field.SetInt(int64(i + 1))
+		case reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		case reflect.Map:
+			field.Set(reflect.MakeMap(field.Type()))
+		case reflect.Ptr:
+			field.Set(reflect.New(field.Type().Elem()))
+		case reflect.Func:
+			field.Set(reflect.MakeFunc(field.Type(), func(args []reflect.Value) []reflect.Value { return nil }))
+		case reflect.Interface:
+			for _, value := range []interface{}{i, NewRingBufferSink(1), &testFormatter{}} {
+				if reflect.TypeOf(value).AssignableTo(field.Type()) {
+					field.Set(reflect.ValueOf(value))
+					break
+				}
+			}
+		}
+		if field.IsZero() {
+			t.Errorf("Expected field %s to be set by the test", v.Type().Field(i).Name)
+		}
+	}
+
+	result := lp.config().logParams(lp.Request)
+	r := reflect.ValueOf(result)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if v.Field(i).Kind() == reflect.Func {
+			if v.Field(i).Pointer() != r.Field(i).Pointer() {
+				t.Errorf("Expected field %s to be kept", name)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v.Field(i).Interface(), r.Field(i).Interface()) {
+			t.Errorf("Expected field %s to be kept, got %v, want: %v", name, r.Field(i), v.Field(i))
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package logparams
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// contextKey is the key of the resultCache in a request context.
+type contextKey struct{}
+
+// Result is the parameters of a request as parsed by a LogParams.
+type Result struct {
+	// String is the formatted parameters, headers and cookies, as returned by ToString.
+	String string
+	// Fields are the parameters, headers and cookies, as returned by ToFields.
+	Fields ParamFields
+}
+
+// resultCache holds the Results of a request once it has been parsed, with the
+// body the first parse read so other configurations can render it again. Requests
+// created with the context of another request, such as outbound requests, don't
+// share its cache.
+type resultCache struct {
+	mu      sync.Mutex
+	request *http.Request
+	body    []byte
+	results []cachedResult
+}
+
+// cachedResult is a Result and the configuration that rendered it.
+type cachedResult struct {
+	config *Config
+	result *Result
+}
+
+// recordBody records the bytes read from a body until it is done.
+type recordBody struct {
+	io.ReadCloser
+	read bytes.Buffer
+	done bool
+}
+
+// Read reads from the body, recording the bytes until done is set.
+func (b *recordBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.done {
+		b.read.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// NewContext returns a copy of ctx where the parameters of the request are cached
+// the first time a LogParams parses them, so later calls to ToString, ToLogger and
+// ToFields, by any LogParams for a request with this context, reuse them without
+// reading the body again. Middleware adds the cache to each request it logs.
+//
+// The cache belongs to the first request parsed with it. A LogParams with another
+// configuration renders the body read by the first parse again, instead of reusing
+// a Result it did not render.
+func NewContext(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(contextKey{}).(*resultCache); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, contextKey{}, &resultCache{})
+}
+
+// FromContext returns the parameters cached in ctx by the first parse, or false if
+// the request has not been parsed. The Fields are shared and must not be modified.
+func FromContext(ctx context.Context) (Result, bool) {
+	cache, ok := ctx.Value(contextKey{}).(*resultCache)
+	if !ok {
+		return Result{}, false
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.results) == 0 {
+		return Result{}, false
+	}
+
+	return *cache.results[0].result, true
+}
+
+// withCache returns r with a cache for its parameters in its context, unless its
+// context already has one for r.
+func withCache(r *http.Request) *http.Request {
+	if cache, ok := r.Context().Value(contextKey{}).(*resultCache); ok {
+		cache.mu.Lock()
+		owned := cache.owns(r)
+		cache.mu.Unlock()
+		if owned {
+			return r
+		}
+	}
+
+	cache := &resultCache{}
+	r = r.WithContext(context.WithValue(r.Context(), contextKey{}, cache))
+	cache.request = r
+	return r
+}
+
+// owns checks if the cache is for r, claiming it if it is not for a request yet.
+// Requests derived from the request with WithContext share its URL.
+func (c *resultCache) owns(r *http.Request) bool {
+	if c.request == nil {
+		c.request = r
+		return true
+	}
+
+	return c.request == r || c.request.URL == r.URL
+}
+
+// cachedParams returns the parameters of the request from its context, parsing and
+// caching them on the first call. A request without a cache is parsed every time.
+func (lp *requestParams) cachedParams() (string, ParamFields) {
+	if cache, ok := lp.Request.Context().Value(contextKey{}).(*resultCache); ok {
+		if result, ok := cache.params(lp); ok {
+			return result.String, result.Fields
+		}
+	}
+
+	return lp.formatParams()
+}
+
+// params returns the parameters of the request of lp rendered with its
+// configuration, parsing them on the first call, or false if the cache is for
+// another request.
+func (c *resultCache) params(lp *requestParams) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.owns(lp.Request) {
+		return nil, false
+	}
+
+	for _, cached := range c.results {
+		if cached.config.renders(lp.Config) {
+			return cached.result, true
+		}
+	}
+
+	var str string
+	var fields ParamFields
+	if len(c.results) == 0 {
+		str, fields = c.recordParams(lp)
+	} else {
+		str, fields = c.renderParams(lp)
+	}
+
+	result := &Result{String: str, Fields: fields}
+	c.results = append(c.results, cachedResult{config: lp.Config, result: result})
+	return result, true
+}
+
+// recordParams parses the request of lp, recording the body it reads. Multipart
+// bodies are not recorded, their form stays parsed on the request.
+func (c *resultCache) recordParams(lp *requestParams) (string, ParamFields) {
+	body := lp.Request.Body
+	if body == nil || body == http.NoBody || isMultipartForm(lp.Request.Header.Get("Content-Type")) {
+		return lp.formatParams()
+	}
+
+	recorder := &recordBody{ReadCloser: body}
+	lp.Request.Body = recorder
+	str, fields := lp.formatParams()
+	recorder.done = true
+	if lp.Request.Body == recorder {
+		lp.Request.Body = body
+	}
+	c.body = recorder.read.Bytes()
+
+	return str, fields
+}
+
+// renderParams renders the recorded body with the configuration of lp, on a copy
+// of its request so the body left for the handler is not read.
+func (c *resultCache) renderParams(lp *requestParams) (string, ParamFields) {
+	p := *lp
+	p.Request = lp.Request.WithContext(lp.Request.Context())
+	p.Request.Body = http.NoBody
+	if c.body != nil {
+		p.Request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	}
+
+	return p.formatParams()
+}
+
+// renders checks if c renders the parameters of a request like other, so a Result
+// of one is the Result of the other. The settings of the lifecycle lines, response
+// body, sampling and sink don't change the Result. Configurations with functions
+// only match themselves.
+func (c *Config) renders(other *Config) bool {
+	if c == other {
+		return true
+	}
+
+	a, b := *c, *other
+	for _, config := range []*Config{&a, &b} {
+		config.ShowResponseBody, config.MaxResponseBodySize = false, 0
+		config.ShowLifecycle, config.HandlerName, config.Clock = false, nil, nil
+		config.Sampler, config.Sink = nil, nil
+	}
+
+	return reflect.DeepEqual(a, b)
+}
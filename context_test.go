@@ -0,0 +1,143 @@
+package logparams
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Request context cache
+
+func TestToFieldsReusesParsedParams(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"foo":"bar"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(NewContext(req.Context()))
+
+	lp := LogParams{Request: req}
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	if string(body) != `{"foo":"bar"}` {
+		t.Errorf("Expected body was incorrect, got %s, want: %s", body, `{"foo":"bar"}`)
+	}
+
+	req.Body = ioutil.NopCloser(strings.NewReader(`{"foo":"baz"}`))
+	if fields := lp.ToFields(); fields.Json["foo"] != "bar" {
+		t.Errorf("Expected cached field was incorrect, got %v, want: %s", fields.Json["foo"], "bar")
+	}
+	if lp.Request != req {
+		t.Errorf("Expected request to be left as set")
+	}
+}
+
+func TestCachedParamsRenderedPerConfig(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New(&out, "", 0)
+
+	var rails, redacted, formatted string
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		rails = (&LogParams{Request: r, ShowPassword: true}).ToString()
+		redacted = (&LogParams{Request: r, Redact: []RedactRule{{Key: "ssn"}}}).ToString()
+		formatted = (&LogParams{Request: r, Formatter: JSONFormatter}).ToString()
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"password":"hunter2","ssn":"123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	Middleware(logger, LogParams{ShowPassword: true})(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	expectedResults := "Parameters: {\"password\" => \"hunter2\", \"ssn\" => \"123\"}"
+	if rails != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", rails, expectedResults)
+	}
+
+	expectedResults = "Parameters: {\"password\" => \"[FILTERED]\", \"ssn\" => \"[FILTERED]\"}"
+	if redacted != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", redacted, expectedResults)
+	}
+
+	expectedResults = `{"json":{"password":"[FILTERED]","ssn":"123"}}`
+	if formatted != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", formatted, expectedResults)
+	}
+}
+
+func TestFromContextInHandler(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New(&out, "", 0)
+
+	var result Result
+	var cached bool
+	handler := Middleware(logger, LogParams{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, cached = FromContext(r.Context())
+		r.Body = ioutil.NopCloser(strings.NewReader(`{"foo":"baz"}`))
+
+		lp := LogParams{Request: r}
+		if lp.ToString() != result.String {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), result.String)
+		}
+	}))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"foo":"bar"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !cached {
+		t.Fatalf("Expected parameters to be cached on the request context")
+	}
+
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+	if result.String != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result.String, expectedResults)
+	}
+	if result.Fields.Json["foo"] != "bar" {
+		t.Errorf("Expected field was incorrect, got %v, want: %s", result.Fields.Json["foo"], "bar")
+	}
+}
+
+func TestFromContextNotParsed(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Errorf("Expected no parameters without a cache")
+	}
+
+	if _, ok := FromContext(NewContext(context.Background())); ok {
+		t.Errorf("Expected no parameters before the request is parsed")
+	}
+}
+
+func TestTransportInsideMiddlewareLogsOutboundRequest(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New(&out, "", 0)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: &Transport{Logger: logger}}
+	handler := Middleware(logger, LogParams{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", upstream.URL+"?outbound=1", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Error GET to httptest server: %s", err)
+		}
+		resp.Body.Close()
+
+		if result, _ := FromContext(r.Context()); result.Fields.Query["inbound"] != "1" {
+			t.Errorf("Expected cached inbound parameters, got %+v", result)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?inbound=1&password_in=secret", nil))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[1] != "Parameters: {\"outbound\" => \"1\"}" {
+		t.Errorf("Expected outbound parameters to be logged, got %s", out.String())
+	}
+}
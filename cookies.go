@@ -0,0 +1,84 @@
+package logparams
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultFilterCookies are name fragments of session and CSRF cookies that are
+// always masked when logged, e.g. PHPSESSID, _session_id and csrf_token.
+var DefaultFilterCookies = []string{
+	"sess",
+	"sid",
+	"csrf",
+	"xsrf",
+	"token",
+	"auth",
+}
+
+// parseCookies will parse the request cookies and return a string of cookies.
+func (lp *requestParams) parseCookies() (string, map[string]string) {
+	if !lp.ShowCookies {
+		return "", nil
+	}
+
+	cookies := make(map[string]string)
+	var names []string
+	for _, cookie := range lp.Request.Cookies() {
+		if _, ok := cookies[cookie.Name]; ok || !lp.isLoggedCookie(cookie.Name) {
+			continue
+		}
+
+		value := toValidUTF8(cookie.Value)
+		if lp.isFilteredCookie(cookie.Name) {
+			value = "[FILTERED]"
+		} else {
+			value = lp.truncateValue(value)
+		}
+
+		cookies[cookie.Name] = value
+		names = append(names, cookie.Name)
+	}
+	sort.Strings(names)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for i, name := range names {
+		writePair(buf, i == 0, toValidUTF8(name), cookies[name])
+	}
+
+	return buf.String(), cookies
+}
+
+// isLoggedCookie checks if the cookie is in the LogCookies allowlist.
+func (lp *requestParams) isLoggedCookie(name string) bool {
+	if len(lp.LogCookies) == 0 {
+		return true
+	}
+
+	for _, logged := range lp.LogCookies {
+		if logged == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFilteredCookie checks if the cookie value should be masked.
+func (lp *requestParams) isFilteredCookie(name string) bool {
+	lower := strings.ToLower(name)
+	for _, filtered := range DefaultFilterCookies {
+		if strings.Contains(lower, filtered) {
+			return true
+		}
+	}
+
+	for _, filtered := range lp.FilterCookies {
+		if filtered == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package logparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Cookies
+
+func TestCookiesToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"} Cookies: {\"_app_session\" => \"[FILTERED]\", \"csrf_token\" => \"[FILTERED]\", \"locale\" => \"en\", \"theme\" => \"dark\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, ShowCookies: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	makeCookieRequest(server.URL+"?foo=bar", t)
+}
+
+func TestCookiesAreHiddenByDefault(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Cookies != nil {
+			t.Errorf("Expected cookies to be nil, got %v", lp.ToFields().Cookies)
+		}
+	}))
+
+	defer server.Close()
+
+	makeCookieRequest(server.URL+"?foo=bar", t)
+}
+
+func TestCookiesToField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, ShowCookies: true, LogCookies: []string{"theme", "locale"}, FilterCookies: []string{"locale"}}
+		fields := lp.ToFields()
+		if len(fields.Cookies) != 2 {
+			t.Errorf("Expected cookies were incorrect, got %v", fields.Cookies)
+		}
+		if fields.Cookies["theme"] != "dark" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", fields.Cookies["theme"], "dark")
+		}
+		if fields.Cookies["locale"] != "[FILTERED]" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", fields.Cookies["locale"], "[FILTERED]")
+		}
+	}))
+
+	defer server.Close()
+
+	makeCookieRequest(server.URL, t)
+}
+
+func makeCookieRequest(url string, t *testing.T) {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	req.AddCookie(&http.Cookie{Name: "locale", Value: "en"})
+	req.AddCookie(&http.Cookie{Name: "_app_session", Value: "abc123"})
+	req.AddCookie(&http.Cookie{Name: "csrf_token", Value: "xyz"})
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
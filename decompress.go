@@ -0,0 +1,165 @@
+package logparams
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultMaxDecompressedSize is the decompressed body limit used when
+// MaxDecompressedSize is not set.
+const DefaultMaxDecompressedSize = 10 << 20 // 10MB
+
+// errDecompressedTooLarge is returned when a compressed body expands beyond the
+// configured limit.
+var errDecompressedTooLarge = errors.New("logparams: decompressed body exceeds limit")
+
+// decompressBody will decode the body according to the Content-Encoding header,
+// failing if it decompresses to more than limit bytes. The body is returned as is
+// when no encoding is set.
+func decompressBody(contentEncoding string, body []byte, limit int64) ([]byte, error) {
+	encodings := strings.Split(contentEncoding, ",")
+
+	// Encodings are listed in the order they were applied, so undo them in reverse.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+
+		reader, err := newDecompressReader(encoding, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		body, err = readLimited(reader, limit)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// decompressReader will wrap r to decode it according to the Content-Encoding
+// header as it is read, failing once it decompresses to more than limit bytes.
+// Closing the reader releases the decoders, not r.
+func decompressReader(contentEncoding string, r io.Reader, limit int64) (io.ReadCloser, error) {
+	encodings := strings.Split(contentEncoding, ",")
+
+	closers := make(multiCloser, 0, len(encodings))
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+
+		reader, err := newDecompressReader(encoding, r)
+		if err != nil {
+			closers.Close()
+			return nil, err
+		}
+
+		closers = append(closers, reader)
+		r = &limitedReader{r: reader, n: limit}
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, closers}, nil
+}
+
+// limitedReader fails once more than n bytes have been read from r.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errDecompressedTooLarge
+	}
+
+	return n, err
+}
+
+// multiCloser closes each of the decoders in turn.
+type multiCloser []io.Closer
+
+func (closers multiCloser) Close() error {
+	var err error
+	for _, closer := range closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// maxDecompressedSize returns the configured decompressed body limit.
+func (lp *requestParams) maxDecompressedSize() int64 {
+	if lp.MaxDecompressedSize > 0 {
+		return lp.MaxDecompressedSize
+	}
+
+	return DefaultMaxDecompressedSize
+}
+
+// newDecompressReader returns a reader that decodes r for the given content encoding.
+func newDecompressReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// Most clients send zlib wrapped data for deflate, but some send raw deflate.
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case "br":
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+
+	return nil, errors.New("logparams: unsupported content encoding " + encoding)
+}
+
+// isZlibHeader checks if the two bytes are a valid zlib header.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// readLimited reads all of r, failing if more than limit bytes are produced.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > limit {
+		return nil, errDecompressedTooLarge
+	}
+
+	return b, nil
+}
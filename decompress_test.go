@@ -0,0 +1,117 @@
+package logparams
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressed JSON body
+
+func TestCompressedJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			enc, _ := zstd.NewWriter(w)
+			return enc
+		},
+	}
+
+	for encoding, encoder := range encoders {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			lp := LogParams{Request: r}
+			if lp.ToString() != expectedResults {
+				t.Errorf("Expected string was incorrect for %s, got %s, want: %s", encoding, lp.ToString(), expectedResults)
+			}
+		}))
+
+		makeCompressedJSONRequest(server.URL, encoding, compress([]byte(`{"foo":"bar"}`), encoder), t)
+		server.Close()
+	}
+}
+
+func TestRawDeflateJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	body := compress([]byte(`{"foo":"bar"}`), func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	})
+	makeCompressedJSONRequest(server.URL, "deflate", body, t)
+}
+
+func TestCompressedJSONBodyIsLeftIntact(t *testing.T) {
+	body := compress([]byte(`{"foo":"bar"}`), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		lp.ToString()
+
+		result, _ := ioutil.ReadAll(r.Body)
+		if !bytes.Equal(result, body) {
+			t.Errorf("Expected body to be left compressed, got %v, want: %v", result, body)
+		}
+	}))
+
+	defer server.Close()
+
+	makeCompressedJSONRequest(server.URL, "gzip", body, t)
+}
+
+func TestCompressedJSONBodyExceedsLimit(t *testing.T) {
+	expectedResults := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, MaxDecompressedSize: 8}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	body := compress([]byte(`{"foo":"bar"}`), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	makeCompressedJSONRequest(server.URL, "gzip", body, t)
+}
+
+func compress(b []byte, encoder func(io.Writer) io.WriteCloser) []byte {
+	var buf bytes.Buffer
+	w := encoder(&buf)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+
+func makeCompressedJSONRequest(url string, encoding string, body []byte, t *testing.T) {
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", encoding)
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
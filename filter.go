@@ -0,0 +1,160 @@
+package logparams
+
+import "bytes"
+
+// filterValue will redact, summarize, replace invalid UTF-8 and truncate the value
+// of a parameter for logging. path is the allowlist path of the value. It returns
+// false if the parameter should be left out.
+func (lp *requestParams) filterValue(key string, path string, value string) (string, bool) {
+	redaction, ok := lp.redactRule(key, path)
+	if !ok && !lp.allowed(path) {
+		redaction, ok = lp.AllowlistRedaction, true
+	}
+
+	if ok {
+		if redaction == Remove {
+			return "", false
+		}
+		return lp.redactValue(redaction, value), true
+	}
+
+	return lp.truncateValue(toValidUTF8(lp.summarizeBinary(key, value))), true
+}
+
+// filterJSON will apply the redaction rules, allowlist, MaxValueLength, MaxKeys,
+// MaxArrayElements and MaxDepth to a decoded JSON value, and summarize binary
+// strings, writing the filtered value to buf as it goes. key is the object key v
+// belongs to, path is its allowlist path, and depth is the nesting depth of v,
+// starting at 1 for the body. It returns false if the value should be left out, in
+// which case nothing is written.
+func (lp *requestParams) filterJSON(buf *bytes.Buffer, key string, path string, v interface{}, depth int) (interface{}, bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if lp.MaxDepth > 0 && depth > lp.MaxDepth {
+			writeJSONString(buf, "…(truncated object)")
+			return "…(truncated object)", true
+		}
+		return lp.filterJSONObject(buf, value, path, depth), true
+	case []interface{}:
+		if lp.MaxDepth > 0 && depth > lp.MaxDepth {
+			writeJSONString(buf, "…(truncated array)")
+			return "…(truncated array)", true
+		}
+		buf.WriteByte('[')
+		dropped := 0
+		if lp.MaxArrayElements > 0 && len(value) > lp.MaxArrayElements {
+			value, dropped = value[:lp.MaxArrayElements], len(value)-lp.MaxArrayElements
+		}
+		filtered := value[:0]
+		for _, element := range value {
+			mark := buf.Len()
+			if len(filtered) != 0 {
+				buf.WriteString(", ")
+			}
+			if element, ok := lp.filterJSON(buf, key, path, element, depth+1); ok {
+				filtered = append(filtered, element)
+			} else {
+				buf.Truncate(mark)
+			}
+		}
+		if dropped > 0 {
+			if len(filtered) != 0 {
+				buf.WriteString(", ")
+			}
+			writeJSONString(buf, truncatedElementsValue(dropped))
+			filtered = append(filtered, truncatedElementsValue(dropped))
+		}
+		buf.WriteByte(']')
+		return filtered, true
+	case string:
+		return lp.writeFilteredValue(buf, key, path, value)
+	case nil:
+		buf.WriteString("null")
+		return nil, true
+	}
+
+	if !lp.allowed(path) {
+		return lp.writeFilteredValue(buf, key, path, jsonValueString(v))
+	}
+
+	writeJSONScalar(buf, v)
+	return v, true
+}
+
+// filterJSONObject will filter the object at path and its values in place, and
+// write it to buf in key order.
+func (lp *requestParams) filterJSONObject(buf *bytes.Buffer, object map[string]interface{}, path string, depth int) map[string]interface{} {
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+
+	keys, dropped := lp.truncateKeys(keys)
+	if dropped > 0 {
+		kept := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			kept[k] = true
+		}
+		for k := range object {
+			if !kept[k] {
+				delete(object, k)
+			}
+		}
+	}
+
+	buf.WriteByte('{')
+	first := true
+	for _, k := range keys {
+		mark := buf.Len()
+		writeJSONKey(buf, first, k)
+
+		var value interface{}
+		var ok bool
+		if redaction, redacted := lp.redactRule(k, jsonPath(path, k)); redacted && object[k] != nil {
+			if ok = redaction != Remove; ok {
+				value = lp.redactValue(redaction, jsonValueString(object[k]))
+				writeJSONString(buf, value.(string))
+			}
+		} else {
+			value, ok = lp.filterJSON(buf, k, jsonPath(path, k), object[k], depth+1)
+		}
+
+		if ok {
+			object[k] = value
+			first = false
+		} else {
+			delete(object, k)
+			buf.Truncate(mark)
+		}
+	}
+
+	if dropped > 0 {
+		object[truncatedKey] = truncatedKeysValue(dropped)
+		writeJSONKey(buf, first, truncatedKey)
+		writeJSONString(buf, truncatedKeysValue(dropped))
+	}
+	buf.WriteByte('}')
+
+	return object
+}
+
+// writeFilteredValue will filter a string value and write it to buf unless it is
+// left out.
+func (lp *requestParams) writeFilteredValue(buf *bytes.Buffer, key string, path string, value string) (interface{}, bool) {
+	filtered, ok := lp.filterValue(key, path, value)
+	if ok {
+		writeJSONString(buf, filtered)
+	}
+
+	return filtered, ok
+}
+
+// jsonValueString returns a string value as is, and other values as JSON.
+func jsonValueString(v interface{}) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+
+	b, _ := marshalJSON(v)
+	return string(b)
+}
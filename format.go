@@ -0,0 +1,78 @@
+package logparams
+
+import "bytes"
+
+// Formatter formats the parsed parameters of a request for logging, in place of
+// the Rails style string.
+type Formatter interface {
+	Format(result Result) string
+}
+
+// FormatterFunc is a function used as a Formatter.
+type FormatterFunc func(result Result) string
+
+// Format calls f(result).
+func (f FormatterFunc) Format(result Result) string {
+	return f(result)
+}
+
+// JSONFormatter formats the fields as a JSON object, leaving out empty fields:
+//
+//	{"query":{"foo":"bar"},"headers":{"X-Request-Id":"abc123"}}
+var JSONFormatter Formatter = FormatterFunc(formatJSON)
+
+// jsonFields are the fields written by JSONFormatter.
+type jsonFields struct {
+	Path      map[string]string        `json:"path,omitempty"`
+	Form      map[string]string        `json:"form,omitempty"`
+	Query     map[string]string        `json:"query,omitempty"`
+	Json      map[string]interface{}   `json:"json,omitempty"`
+	JsonArray []map[string]interface{} `json:"json_array,omitempty"`
+	Headers   map[string]string        `json:"headers,omitempty"`
+	Cookies   map[string]string        `json:"cookies,omitempty"`
+}
+
+// formatJSON returns the fields of the result as a JSON object.
+func formatJSON(result Result) string {
+	fields := result.Fields
+	b, err := marshalJSON(jsonFields{
+		Path:      fields.Path,
+		Form:      fields.Form,
+		Query:     fields.Query,
+		Json:      fields.Json,
+		JsonArray: fields.JsonArray,
+		Headers:   fields.Headers,
+		Cookies:   fields.Cookies,
+	})
+	if err != nil {
+		return ""
+	}
+
+	return escapeC1(b)
+}
+
+// escapeC1 will escape the C1 controls encoding/json leaves in the JSON b, so a
+// value can't send the single byte CSI to a terminal. In UTF-8 they are 0xc2
+// followed by 0x80 to 0x9f, and only occur in strings.
+func escapeC1(b []byte) string {
+	const hex = "0123456789abcdef"
+
+	if bytes.IndexByte(b, 0xc2) < 0 {
+		return string(b)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for i := 0; i < len(b); i++ {
+		if b[i] == 0xc2 && i+1 < len(b) && b[i+1] >= 0x80 && b[i+1] <= 0x9f {
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[b[i+1]>>4])
+			buf.WriteByte(hex[b[i+1]&0xf])
+			i++
+			continue
+		}
+		buf.WriteByte(b[i])
+	}
+
+	return buf.String()
+}
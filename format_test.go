@@ -0,0 +1,56 @@
+package logparams
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Formatters
+
+func TestJSONFormatter(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "foo\nbar", "password": "secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	lp := LogParams{Request: req, Formatter: JSONFormatter}
+
+	expected := `{"json":{"name":"foo\nbar","password":"[FILTERED]"}}`
+	if result := lp.ToString(); result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+}
+
+func TestJSONFormatterEscapesC1Controls(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?a=x%C2%9B31m&b=%C2%A9", nil)
+	lp := LogParams{Request: req, Formatter: JSONFormatter}
+
+	expected := `{"query":{"a":"x\u009b31m","b":"©"}}`
+	if result := lp.ToString(); result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+}
+
+func TestFormatterSkipsEmpty(t *testing.T) {
+	called := false
+	lp := LogParams{
+		Request:   httptest.NewRequest("GET", "/", nil),
+		Formatter: FormatterFunc(func(result Result) string { called = true; return "params" }),
+	}
+
+	if result := lp.ToString(); result != "" || called {
+		t.Errorf("Expected empty string without calling the formatter, got %q", result)
+	}
+}
+
+func TestFormatterFunc(t *testing.T) {
+	lp := LogParams{
+		Request: httptest.NewRequest("GET", "/?foo=bar", nil),
+		Formatter: FormatterFunc(func(result Result) string {
+			return "params foo=" + result.Fields.Query["foo"]
+		}),
+	}
+
+	expected := "params foo=bar"
+	if result := lp.ToString(); result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/aaronvb/logparams
 
-go 1.14
+go 1.22
+
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
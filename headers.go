@@ -0,0 +1,72 @@
+package logparams
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultFilterHeaders are the headers that are always masked when logged.
+var DefaultFilterHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+	"X-Xsrf-Token",
+	"X-Amz-Security-Token",
+}
+
+// parseHeaders will parse the allowed request headers and return a string of headers.
+func (lp *requestParams) parseHeaders() (string, map[string]string) {
+	if len(lp.LogHeaders) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(lp.LogHeaders))
+	seen := make(map[string]bool, len(lp.LogHeaders))
+	for _, name := range lp.LogHeaders {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := lp.Request.Header[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	sort.Strings(names)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	headers := make(map[string]string, len(names))
+	for i, name := range names {
+		value := toValidUTF8(strings.Join(lp.Request.Header[name], ", "))
+		if lp.isFilteredHeader(name) {
+			value = "[FILTERED]"
+		} else {
+			value = lp.truncateValue(value)
+		}
+
+		headers[name] = value
+		writePair(buf, i == 0, name, value)
+	}
+
+	return buf.String(), headers
+}
+
+// isFilteredHeader checks if the header value should be masked.
+func (lp *requestParams) isFilteredHeader(name string) bool {
+	for _, filtered := range DefaultFilterHeaders {
+		if strings.EqualFold(filtered, name) {
+			return true
+		}
+	}
+
+	for _, filtered := range lp.FilterHeaders {
+		if strings.EqualFold(filtered, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,87 @@
+package logparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Headers
+
+func TestHeadersToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"} Headers: {\"Authorization\" => \"[FILTERED]\", \"X-Request-Id\" => \"abc123\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, LogHeaders: []string{"X-Request-ID", "Authorization", "X-Missing"}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"?foo=bar", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestHeadersToField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, LogHeaders: []string{"User-Agent", "X-Tenant"}, FilterHeaders: []string{"x-tenant"}}
+		fields := lp.ToFields()
+		if fields.Headers["User-Agent"] != "logparams-test" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", fields.Headers["User-Agent"], "logparams-test")
+		}
+		if fields.Headers["X-Tenant"] != "[FILTERED]" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", fields.Headers["X-Tenant"], "[FILTERED]")
+		}
+		if fields.Form["foo"] != "bar" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", fields.Form["foo"], "bar")
+		}
+	}))
+
+	defer server.Close()
+
+	params := url.Values{}
+	params.Set("foo", "bar")
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "logparams-test")
+	req.Header.Set("X-Tenant", "acme")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestHeadersWithoutParamsToString(t *testing.T) {
+	expectedResults := "Headers: {\"X-Request-Id\" => \"abc123\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, LogHeaders: []string{"X-Request-ID"}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("X-Request-ID", "abc123")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
@@ -0,0 +1,260 @@
+package logparams
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Logger logs the parameters of many requests with a configuration built once by
+// New. It is safe for concurrent use.
+//
+//	params := logparams.New(
+//		logparams.WithRedact(logparams.RedactRule{Key: "ssn", Redaction: logparams.Remove}),
+//		logparams.WithFormatter(logparams.JSONFormatter),
+//	)
+//	http.Handle("/", params.Middleware(logger)(handler))
+//
+// LogParams remains for compatibility, a LogParams with the same fields as the
+// Config logs the same parameters.
+type Logger struct {
+	config Config
+}
+
+// Option configures a Logger, or a Route on top of the Default of its Routes.
+type Option func(*Config)
+
+// New returns a Logger configured by the options.
+func New(options ...Option) *Logger {
+	l := &Logger{}
+	for _, option := range options {
+		option(&l.config)
+	}
+
+	return l
+}
+
+// Params returns a LogParams for the request with the configuration of the Logger.
+func (l *Logger) Params(r *http.Request) *LogParams {
+	lp := l.config.logParams(r)
+	return &lp
+}
+
+// ToString will return a string of all parameters within the http request.
+func (l *Logger) ToString(r *http.Request) string {
+	return l.config.params(r).toString()
+}
+
+// ToLogger will log print all parameters within the http request.
+func (l *Logger) ToLogger(r *http.Request, logger *log.Logger) {
+	l.config.params(r).toLogger(logger)
+}
+
+// ToFields will return all parameters within the http request as ParamFields.
+func (l *Logger) ToFields(r *http.Request) ParamFields {
+	return l.config.params(r).toFields()
+}
+
+// ToSink will send all parameters within the http request, with the request
+// metadata, to the sink.
+func (l *Logger) ToSink(r *http.Request, sink Sink) error {
+	return l.config.params(r).toSink(sink)
+}
+
+// Middleware returns a http middleware that logs the parameters of each request,
+// see Middleware.
+func (l *Logger) Middleware(logger *log.Logger) func(http.Handler) http.Handler {
+	return configMiddleware(logger, func(r *http.Request) (*Config, bool) {
+		return &l.config, true
+	})
+}
+
+// Transport returns a Transport sending requests with base, see Transport.
+func (l *Logger) Transport(base http.RoundTripper, logger *log.Logger, logResponse bool) *Transport {
+	return &Transport{Base: base, Logger: logger, Params: l.config.logParams(nil), LogResponse: logResponse}
+}
+
+// WithShowEmpty will log and return the parameters even when they are empty.
+func WithShowEmpty() Option {
+	return func(c *Config) { c.ShowEmpty = true }
+}
+
+// WithShowPassword will log password parameters.
+func WithShowPassword() Option {
+	return func(c *Config) { c.ShowPassword = true }
+}
+
+// WithHidePrefix will leave out the "Parameters:" prefix.
+func WithHidePrefix() Option {
+	return func(c *Config) { c.HidePrefix = true }
+}
+
+// WithMaxDecompressedSize limits the size of a decompressed body.
+func WithMaxDecompressedSize(size int64) Option {
+	return func(c *Config) { c.MaxDecompressedSize = size }
+}
+
+// WithBodyMethods will only log the body for these HTTP methods.
+func WithBodyMethods(methods ...string) Option {
+	return func(c *Config) { c.AllowBodyMethods = extend(c.AllowBodyMethods, methods...) }
+}
+
+// WithoutBodyMethods will not log the body for these HTTP methods.
+func WithoutBodyMethods(methods ...string) Option {
+	return func(c *Config) { c.DenyBodyMethods = extend(c.DenyBodyMethods, methods...) }
+}
+
+// WithHeaders will log these request headers.
+func WithHeaders(names ...string) Option {
+	return func(c *Config) { c.LogHeaders = extend(c.LogHeaders, names...) }
+}
+
+// WithFilterHeaders will mask these headers in addition to DefaultFilterHeaders.
+func WithFilterHeaders(names ...string) Option {
+	return func(c *Config) { c.FilterHeaders = extend(c.FilterHeaders, names...) }
+}
+
+// WithCookies will log the request cookies, only these cookies if any are given.
+func WithCookies(names ...string) Option {
+	return func(c *Config) {
+		c.ShowCookies = true
+		c.LogCookies = extend(c.LogCookies, names...)
+	}
+}
+
+// WithFilterCookies will mask these cookies in addition to DefaultFilterCookies.
+func WithFilterCookies(names ...string) Option {
+	return func(c *Config) { c.FilterCookies = extend(c.FilterCookies, names...) }
+}
+
+// WithPathParams will log the router path parameters returned by params.
+func WithPathParams(params PathParamsFunc) Option {
+	return func(c *Config) { c.PathParams = params }
+}
+
+// WithResponseBody will log JSON response bodies up to maxSize in Middleware
+// (0 for the default).
+func WithResponseBody(maxSize int) Option {
+	return func(c *Config) {
+		c.ShowResponseBody = true
+		c.MaxResponseBodySize = maxSize
+	}
+}
+
+// WithLifecycle will log the Started and Processing lines in Middleware, with the
+// handler name from name if it is not nil.
+func WithLifecycle(name HandlerNameFunc) Option {
+	return func(c *Config) {
+		c.ShowLifecycle = true
+		c.HandlerName = name
+	}
+}
+
+// WithClock returns the current time from clock.
+func WithClock(clock func() time.Time) Option {
+	return func(c *Config) { c.Clock = clock }
+}
+
+// WithMaxValueLength limits the length of logged values.
+func WithMaxValueLength(length int) Option {
+	return func(c *Config) { c.MaxValueLength = length }
+}
+
+// WithMaxKeys limits the number of logged keys of each object.
+func WithMaxKeys(keys int) Option {
+	return func(c *Config) { c.MaxKeys = keys }
+}
+
+// WithMaxDepth limits the nesting depth of logged JSON values.
+func WithMaxDepth(depth int) Option {
+	return func(c *Config) { c.MaxDepth = depth }
+}
+
+// WithMaxOutputSize limits the size of the logged parameters.
+func WithMaxOutputSize(size int) Option {
+	return func(c *Config) { c.MaxOutputSize = size }
+}
+
+// WithMaxArrayElements limits the logged elements of JSON arrays.
+func WithMaxArrayElements(elements int) Option {
+	return func(c *Config) { c.MaxArrayElements = elements }
+}
+
+// WithSummarizeBinary will replace binary, base64 and hex values of at least
+// minLength with a summary (0 for the default).
+func WithSummarizeBinary(minLength int) Option {
+	return func(c *Config) {
+		c.SummarizeBinary = true
+		c.MinBinaryLength = minLength
+	}
+}
+
+// WithBinaryKeys will always (true) or never (false) summarize the values of these keys.
+func WithBinaryKeys(keys map[string]bool) Option {
+	return func(c *Config) {
+		binaryKeys := make(map[string]bool, len(c.BinaryKeys)+len(keys))
+		for k, v := range c.BinaryKeys {
+			binaryKeys[k] = v
+		}
+		for k, v := range keys {
+			binaryKeys[k] = v
+		}
+		c.BinaryKeys = binaryKeys
+	}
+}
+
+// WithRedact adds rules for redacting parameters.
+func WithRedact(rules ...RedactRule) Option {
+	return func(c *Config) { c.Redact = extend(c.Redact, rules...) }
+}
+
+// WithHMACKey sets the secret key for the HMAC redaction.
+func WithHMACKey(key []byte) Option {
+	return func(c *Config) { c.HMACKey = append([]byte(nil), key...) }
+}
+
+// WithAllowlist will only log the parameters in keys, logging the others with
+// redaction.
+func WithAllowlist(redaction Redaction, keys ...string) Option {
+	return func(c *Config) {
+		c.Allowlist = true
+		c.AllowlistRedaction = redaction
+		c.AllowKeys = extend(c.AllowKeys, keys...)
+	}
+}
+
+// WithBodyStruct redacts parameters with the log tags of v, see StructRules.
+func WithBodyStruct(v interface{}) Option {
+	return func(c *Config) { c.BodyStruct = v }
+}
+
+// WithSpec redacts the sensitive parameters of spec.
+func WithSpec(spec *Spec) Option {
+	return func(c *Config) { c.Spec = spec }
+}
+
+// WithStreamJSON will render JSON bodies token by token.
+func WithStreamJSON() Option {
+	return func(c *Config) { c.StreamJSON = true }
+}
+
+// WithSampler selects the requests logged in Middleware.
+func WithSampler(sampler *Sampler) Option {
+	return func(c *Config) { c.Sampler = sampler }
+}
+
+// WithSink sends the parameters to sink instead of the logger.
+func WithSink(sink Sink) Option {
+	return func(c *Config) { c.Sink = sink }
+}
+
+// WithFormatter formats the parameters with formatter.
+func WithFormatter(formatter Formatter) Option {
+	return func(c *Config) { c.Formatter = formatter }
+}
+
+// extend returns s with values appended, without writing to the array of s, so
+// options can extend a configuration shared with other requests.
+func extend[T any](s []T, values ...T) []T {
+	return append(s[:len(s):len(s)], values...)
+}
@@ -0,0 +1,134 @@
+package logparams
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Logger
+
+func TestNewMatchesLogParams(t *testing.T) {
+	body := `{"name": "foo", "password": "secret", "ssn": "123-45-6789", "token": "abcdef"}`
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/users?page=1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "abc123")
+		return req
+	}
+
+	l := New(
+		WithHeaders("X-Request-Id"),
+		WithRedact(RedactRule{Key: "ssn", Redaction: Remove}, RedactRule{Key: "token", Redaction: PartialMask}),
+		WithMaxValueLength(4),
+	)
+	lp := LogParams{
+		Request:        newRequest(),
+		LogHeaders:     []string{"X-Request-Id"},
+		Redact:         []RedactRule{{Key: "ssn", Redaction: Remove}, {Key: "token", Redaction: PartialMask}},
+		MaxValueLength: 4,
+	}
+
+	result, expected := l.ToString(newRequest()), lp.ToString()
+	if result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+}
+
+func TestLoggerReusedAcrossRequests(t *testing.T) {
+	l := New(WithHidePrefix())
+
+	for _, value := range []string{"foo", "bar", "baz"} {
+		req := httptest.NewRequest("GET", "/?q="+value, nil)
+		expected := fmt.Sprintf(`{"q" => "%s"}`, value)
+		if result := l.ToString(req); result != expected {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+		}
+	}
+}
+
+func TestLoggerConcurrentRequests(t *testing.T) {
+	l := New(WithRedact(RedactRule{Key: "card", Redaction: PartialMask}), WithFormatter(JSONFormatter))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"id": %d, "card": "4111111111111111"}`, i)
+			req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			expected := fmt.Sprintf(`{"json":{"card":"************1111","id":%d}}`, i)
+			if result := l.ToString(req); result != expected {
+				t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestOptionsCopyArguments(t *testing.T) {
+	headers := []string{"X-Request-Id"}
+	l := New(WithHeaders(headers...))
+	headers[0] = "Authorization"
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "abc123")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	expected := `Headers: {"X-Request-Id" => "abc123"}`
+	if result := l.ToString(req); result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+}
+
+func TestLoggerToFields(t *testing.T) {
+	l := New()
+	fields := l.ToFields(httptest.NewRequest("GET", "/?foo=bar", nil))
+
+	if fields.Query["foo"] != "bar" {
+		t.Errorf("Expected query was incorrect, got %v, want: map[foo:bar]", fields.Query)
+	}
+}
+
+func TestLoggerToSink(t *testing.T) {
+	sink := NewRingBufferSink(10)
+	l := New(WithShowPassword())
+	req := httptest.NewRequest("GET", "/?password=hunter2", nil)
+	if err := l.ToSink(req, sink); err != nil {
+		t.Fatalf("Error logging to sink: %s", err)
+	}
+
+	expected := `Parameters: {"password" => "hunter2"}`
+	if entries := sink.Entries(); len(entries) != 1 || entries[0].String != expected {
+		t.Errorf("Expected entries were incorrect, got %v, want: %s", entries, expected)
+	}
+}
+
+func TestLoggerMiddlewareToLogger(t *testing.T) {
+	expectedResults := regexp.MustCompile(`^\{"query":\{"foo":"bar"\}\}\nCompleted 200 OK in \d+ms\n$`)
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(New(WithFormatter(JSONFormatter)).Middleware(&logger)(handler))
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?foo=bar")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	if !expectedResults.MatchString(str.String()) {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
@@ -3,70 +3,103 @@ package logparams
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
+	"time"
 )
 
-// LogParams struct
-// Request is the http request
-// HideEmpty will not log or return "" if param is empty.
-// FilterPassword will filter password parameters (default true).
+// LogParams is the Request to log with its configuration, the fields of Config. It
+// is kept for compatibility, New builds a Logger with the configuration once to
+// log many requests.
+// Request is the http request.
 type LogParams struct {
-	Request      *http.Request
-	ShowEmpty    bool
-	ShowPassword bool
-	HidePrefix   bool
+	Request             *http.Request
+	ShowEmpty           bool
+	ShowPassword        bool
+	HidePrefix          bool
+	MaxDecompressedSize int64
+	AllowBodyMethods    []string
+	DenyBodyMethods     []string
+	LogHeaders          []string
+	FilterHeaders       []string
+	ShowCookies         bool
+	LogCookies          []string
+	FilterCookies       []string
+	PathParams          PathParamsFunc
+	ShowResponseBody    bool
+	MaxResponseBodySize int
+	ShowLifecycle       bool
+	HandlerName         HandlerNameFunc
+	Clock               func() time.Time
+	MaxValueLength      int
+	MaxKeys             int
+	MaxDepth            int
+	MaxOutputSize       int
+	SummarizeBinary     bool
+	BinaryKeys          map[string]bool
+	MinBinaryLength     int
+	Redact              []RedactRule
+	HMACKey             []byte
+	Allowlist           bool
+	AllowKeys           []string
+	AllowlistRedaction  Redaction
+	BodyStruct          interface{}
+	Spec                *Spec
+	MaxArrayElements    int
+	StreamJSON          bool
+	Sampler             *Sampler
+	Sink                Sink
+	Formatter           Formatter
 }
 
 type ParamFields struct {
+	Path      map[string]string
 	Form      map[string]string
 	Query     map[string]string
 	Json      map[string]interface{}
 	JsonArray []map[string]interface{}
+	Headers   map[string]string
+	Cookies   map[string]string
 }
 
 // ToString will return a string of all parameters within the http request.
 func (lp *LogParams) ToString() string {
-	paramsString, _ := lp.parseParams()
-	if !lp.ShowEmpty && paramsString == "" {
-		return ""
-	}
+	return lp.params().toString()
+}
 
-	var str string
-	if lp.HidePrefix {
-		str = paramsString
-	} else {
-		str = fmt.Sprintf("Parameters: %s", paramsString)
-	}
+// ToLogger will log print all parameters within the http request.
+func (lp *LogParams) ToLogger(logger *log.Logger) {
+	lp.params().toLogger(logger)
+}
+
+// ToFields will return all parameters within the http request in a struct.
+func (lp *LogParams) ToFields() ParamFields {
+	return lp.params().toFields()
+}
 
+// toString will return a string of all parameters within the request.
+func (lp *requestParams) toString() string {
+	str, _ := lp.cachedParams()
 	return str
 }
 
-// ToLogger will log print all parameters within the http request.
-func (lp *LogParams) ToLogger(logger *log.Logger) {
-	paramsString, _ := lp.parseParams()
-	if !lp.ShowEmpty && paramsString == "" {
+// toLogger will log print all parameters within the request.
+func (lp *requestParams) toLogger(logger *log.Logger) {
+	str, _ := lp.cachedParams()
+	if !lp.ShowEmpty && str == "" {
 		return
 	}
 
-	var str string
-	if lp.HidePrefix {
-		str = paramsString
-	} else {
-		str = fmt.Sprintf("Parameters: %s", paramsString)
-	}
-
-	logger.Printf(str)
+	logger.Print(str)
 }
 
-// ToLogger will log print all parameters within the http request.
-func (lp *LogParams) ToFields() ParamFields {
-	paramsString, fields := lp.parseParams()
-	if !lp.ShowEmpty && paramsString == "" {
+// toFields will return all parameters within the request in a struct.
+func (lp *requestParams) toFields() ParamFields {
+	str, fields := lp.cachedParams()
+	if !lp.ShowEmpty && str == "" {
 		return ParamFields{}
 	}
 
@@ -75,165 +108,214 @@ func (lp *LogParams) ToFields() ParamFields {
 
 // Helper methods
 
-// checkForFormParams checks for form params in the request.
-func (lp *LogParams) checkForFormParams() bool {
-	err := lp.Request.ParseForm()
-	if err != nil {
-		return false
+// isMultipartForm checks for content-type multipart/form-data.
+func isMultipartForm(contentType string) bool {
+	return strings.Contains(contentType, "multipart/form-data")
+}
+
+// formatParams will return the formatted path and request parameters, headers and cookies of the request,
+// and the fields they were built from.
+func (lp *requestParams) formatParams() (string, ParamFields) {
+	paramsString, fields := lp.parseParams()
+	pathString, pathParams := lp.parsePathParams()
+	paramsString = mergePathParams(pathString, paramsString)
+	fields.Path = pathParams
+	headersString, headers := lp.parseHeaders()
+	fields.Headers = headers
+	cookiesString, cookies := lp.parseCookies()
+	fields.Cookies = cookies
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if lp.ShowEmpty || paramsString != "" {
+		if !lp.HidePrefix {
+			buf.WriteString("Parameters: ")
+		}
+		buf.WriteString(paramsString)
 	}
 
-	if len(lp.Request.PostForm) == 0 {
-		return false
+	if headersString != "" {
+		writeSection(buf, "Headers: {", headersString)
 	}
 
-	return true
-}
+	if cookiesString != "" {
+		writeSection(buf, "Cookies: {", cookiesString)
+	}
 
-// checkForQueryParams checks for query params in the request.
-func (lp *LogParams) checkForQueryParams() bool {
-	return len(lp.Request.URL.Query()) != 0
-}
+	str := lp.truncateOutput(buf.String())
+	if lp.Formatter != nil && str != "" {
+		str = lp.formatOutput(Result{String: str, Fields: fields})
+	}
 
-// checkForJSON checks for content-type application/json in the header.
-func (lp *LogParams) checkForJSON() bool {
-	matched, _ := regexp.MatchString(`application\/json`, lp.Request.Header.Get("Content-Type"))
-	return matched
+	return str, fields
 }
 
-// checkForJSON checks for content-type multipart/form-data in the header.
-func (lp *LogParams) checkForMultipartForm() bool {
-	matched, _ := regexp.MatchString(`multipart\/form-data`, lp.Request.Header.Get("Content-Type"))
-	return matched
+// writeSection will write a braced section, separated from the previous one.
+func writeSection(buf *bytes.Buffer, prefix string, str string) {
+	if buf.Len() != 0 {
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(prefix)
+	buf.WriteString(str)
+	buf.WriteByte('}')
 }
 
 // parseParams will check the type of param in the request and call the correct parser.
-func (lp *LogParams) parseParams() (string, ParamFields) {
-	if lp.checkForFormParams() {
-		str, fields := lp.parseFormParams(false)
-		str = fmt.Sprintf("{%s}", str)
-		return str, fields
-	} else if lp.checkForQueryParams() {
-		str, fields := lp.parseQueryParams()
-		str = fmt.Sprintf("{%s}", str)
-		return str, fields
-	} else if lp.checkForJSON() {
-		str, fields := lp.parseJSONBody()
-		return str, fields
-	} else if lp.checkForMultipartForm() {
-		str, fields := lp.parseFormParams(true)
-		str = fmt.Sprintf("{%s}", str)
-		return str, fields
+// Form values take precedence over query parameters, then the JSON and multipart bodies.
+func (lp *requestParams) parseParams() (string, ParamFields) {
+	bodyMethod := lp.checkBodyMethod()
+	if bodyMethod {
+		if form, err := lp.postForm(); err == nil && len(form) != 0 {
+			str, formFields := lp.parseFormParams(form)
+			return str, ParamFields{Form: formFields}
+		}
+	}
+
+	if query := lp.Request.URL.Query(); len(query) != 0 {
+		str, queryFields := lp.parseQueryParams(query)
+		return str, ParamFields{Query: queryFields}
+	}
+
+	if !bodyMethod {
+		return "", ParamFields{}
+	}
+
+	contentType := lp.Request.Header.Get("Content-Type")
+	if isJSONContentType(contentType) {
+		return lp.parseJSONBody()
+	}
+
+	if isMultipartForm(contentType) {
+		if err := lp.Request.ParseMultipartForm(32 << 20); err != nil { // Max 32MB
+			return "", ParamFields{}
+		}
+		str, formFields := lp.parseFormParams(lp.Request.PostForm)
+		return str, ParamFields{Form: formFields}
 	}
 
 	return "", ParamFields{}
 }
 
 // parseFormParams will parse the form for values and return a string of parameters
-func (lp *LogParams) parseFormParams(multipart bool) (string, ParamFields) {
-	var paramString string
+func (lp *requestParams) parseFormParams(form url.Values) (string, map[string]string) {
+	decoder := lp.bodyDecoder()
+	keys, dropped := lp.truncateKeys(valueKeys(form))
+	formFields := make(map[string]string, len(keys))
 
-	if multipart {
-		err := lp.Request.ParseMultipartForm(32 << 20) // Max 32MB
-		if err != nil {
-			return paramString, ParamFields{}
-		}
-	} else {
-		err := lp.Request.ParseForm()
-		if err != nil {
-			return paramString, ParamFields{}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteByte('{')
+	for _, k := range keys {
+		formValue, ok := lp.filterValue(k, k, transcodeString(decoder, form.Get(k)))
+		if !ok {
+			continue
 		}
+
+		key := decodeString(decoder, k)
+		writePair(buf, len(formFields) == 0, key, formValue)
+		formFields[key] = formValue
 	}
 
-	var paramCount = 0
-	formFields := ParamFields{Form: make(map[string]string, len(lp.Request.PostForm))}
-	for k := range lp.Request.PostForm {
-		if k == "password" || k == "password_confirmation" {
-			formFields.Form[k] = "[FILTERED]"
-			paramString += fmt.Sprintf("\"%s\" => \"%s\"", k, "[FILTERED]")
-		} else {
-			formValue := lp.Request.PostForm.Get(k)
-			formFields.Form[k] = formValue
-			paramString += fmt.Sprintf("\"%s\" => \"%s\"", k, formValue)
-		}
-		paramCount++
-		if paramCount != len(lp.Request.PostForm) {
-			paramString += ", "
-		}
+	if dropped > 0 {
+		writePair(buf, len(formFields) == 0, truncatedKey, truncatedKeysValue(dropped))
+		formFields[truncatedKey] = truncatedKeysValue(dropped)
 	}
+	buf.WriteByte('}')
 
-	return paramString, formFields
+	return buf.String(), formFields
 }
 
 // parseQueryParams will parse query parameters in the URL.
-func (lp *LogParams) parseQueryParams() (string, ParamFields) {
-	var paramString string
-
-	var paramCount = 0
-	formFields := ParamFields{Query: make(map[string]string, len(lp.Request.URL.Query()))}
-	for k := range lp.Request.URL.Query() {
-		paramValue := lp.Request.URL.Query()[k][0]
-		formFields.Query[k] = paramValue
-		paramString += fmt.Sprintf("\"%s\" => \"%s\"", k, paramValue)
-		paramCount++
-		if paramCount != len(lp.Request.URL.Query()) {
-			paramString += ", "
+func (lp *requestParams) parseQueryParams(query url.Values) (string, map[string]string) {
+	keys, dropped := lp.truncateKeys(valueKeys(query))
+	queryFields := make(map[string]string, len(keys))
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteByte('{')
+	for _, k := range keys {
+		paramValue, ok := lp.filterValue(k, k, query[k][0])
+		if !ok {
+			continue
 		}
+
+		key := toValidUTF8(k)
+		writePair(buf, len(queryFields) == 0, key, paramValue)
+		queryFields[key] = paramValue
 	}
 
-	return paramString, formFields
+	if dropped > 0 {
+		writePair(buf, len(queryFields) == 0, truncatedKey, truncatedKeysValue(dropped))
+		queryFields[truncatedKey] = truncatedKeysValue(dropped)
+	}
+	buf.WriteByte('}')
+
+	return buf.String(), queryFields
 }
 
 // parseJSONBody will parse the json in the body as parameters.
-func (lp *LogParams) parseJSONBody() (string, ParamFields) {
-	var result map[string]interface{}
-	var resultArray []map[string]interface{}
+func (lp *requestParams) parseJSONBody() (string, ParamFields) {
+	if lp.StreamJSON {
+		return lp.streamJSONBody()
+	}
 
 	body, _ := ioutil.ReadAll(lp.Request.Body)
 	lp.Request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	err := json.Unmarshal(body, &result)
+	body, err := decompressBody(lp.Request.Header.Get("Content-Encoding"), body, lp.maxDecompressedSize())
 	if err != nil {
-		err := json.Unmarshal(body, &resultArray)
-		if err != nil {
-			return "", ParamFields{}
-		}
+		return "", ParamFields{}
+	}
+	body = decodeBytes(lp.bodyDecoder(), body)
+
+	return lp.renderJSON(body)
+}
+
+// renderJSON will filter and render the json object or array of objects in body.
+func (lp *requestParams) renderJSON(body []byte) (string, ParamFields) {
+	var result map[string]interface{}
+	var resultArray []map[string]interface{}
+
+	var err error
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) != 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(body, &resultArray)
+	} else {
+		err = json.Unmarshal(body, &result)
+	}
+	if err != nil {
+		return "", ParamFields{}
 	}
 
-	var b []byte
+	buf := getBuffer()
+	defer putBuffer(buf)
 	if len(result) != 0 {
-		if !lp.ShowPassword {
-			if result["password"] != nil {
-				result["password"] = "[FILTERED]"
-			}
-			if result["password_confirmation"] != nil {
-				result["password_confirmation"] = "[FILTERED]"
-			}
-		}
-		b, err = json.Marshal(&result)
-		if err != nil {
-			return "", ParamFields{}
-		}
+		lp.filterJSONObject(buf, result, "", 1)
 	} else if len(resultArray) != 0 {
-		if !lp.ShowPassword {
-			for _, v := range resultArray {
-				if v["password"] != nil {
-					v["password"] = "[FILTERED]"
-				}
-				if v["password_confirmation"] != nil {
-					v["password_confirmation"] = "[FILTERED]"
-				}
+		buf.WriteByte('[')
+		for i, v := range resultArray {
+			if i != 0 {
+				buf.WriteString(", ")
 			}
+			lp.filterJSONObject(buf, v, "", 1)
 		}
-		b, err = json.Marshal(&resultArray)
-		if err != nil {
-			return "", ParamFields{}
-		}
+		buf.WriteByte(']')
 	}
 
-	str := string(b)
-	str = strings.Replace(str, "\":\"", "\" => \"", -1)
-	str = strings.Replace(str, "\":{", "\" => {", -1)
-	str = strings.Replace(str, "\",\"", "\", \"", -1)
-	str = strings.Replace(str, "},{", "}, {", -1)
 	fields := ParamFields{Json: result, JsonArray: resultArray}
-	return fmt.Sprint(str), fields
+	return buf.String(), fields
+}
+
+// marshalJSON will marshal v without escaping HTML characters, which would make
+// values like "<binary ...>" harder to read in logs.
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...), nil
 }
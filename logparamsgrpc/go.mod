@@ -0,0 +1,21 @@
+module github.com/aaronvb/logparams/logparamsgrpc
+
+go 1.22
+
+require (
+	github.com/aaronvb/logparams v0.0.0
+	google.golang.org/grpc v1.67.3
+	google.golang.org/protobuf v1.35.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.2.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/aaronvb/logparams => ../
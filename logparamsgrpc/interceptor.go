@@ -0,0 +1,181 @@
+// Package logparamsgrpc logs the fields of gRPC request messages as parameters,
+// with the same filtering and output as logparams does for HTTP requests.
+//
+// Messages are rendered with protojson and logged as if they were the JSON body of
+// a POST to the full method name, which is how gRPC is sent over HTTP/2. Incoming
+// and outgoing metadata is used as the request headers.
+package logparamsgrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aaronvb/logparams"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// discardLogger drops the lines of an Interceptor without a Logger.
+var discardLogger = log.New(io.Discard, "", 0)
+
+// Interceptor logs the request messages of gRPC calls.
+//
+//	interceptor := &logparamsgrpc.Interceptor{Logger: logger}
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(interceptor.UnaryServer()),
+//		grpc.StreamInterceptor(interceptor.StreamServer()),
+//	)
+type Interceptor struct {
+	// Logger receives the log lines, unless Params has a Sink.
+	Logger *log.Logger
+	// Params is used as the configuration for every message, its Request is ignored.
+	Params logparams.LogParams
+	// IncludeMethods will only log these methods (default all). A name ending in "/"
+	// matches every method of a service, e.g. "/helloworld.Greeter/".
+	IncludeMethods []string
+	// ExcludeMethods will not log these methods.
+	ExcludeMethods []string
+}
+
+// UnaryServer returns a grpc.UnaryServerInterceptor that logs the request message.
+func (i *Interceptor) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		i.logMessage(ctx, info.FullMethod, md, req)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServer returns a grpc.StreamServerInterceptor that logs every message
+// received from the client.
+func (i *Interceptor) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, interceptor: i, method: info.FullMethod})
+	}
+}
+
+// UnaryClient returns a grpc.UnaryClientInterceptor that logs the request message.
+func (i *Interceptor) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		i.logMessage(ctx, method, md, req)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClient returns a grpc.StreamClientInterceptor that logs every message
+// sent to the server.
+func (i *Interceptor) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &clientStream{ClientStream: cs, interceptor: i, method: method}, nil
+	}
+}
+
+// serverStream logs the messages received on a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	interceptor *Interceptor
+	method      string
+}
+
+// RecvMsg receives a message from the client and logs it.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		md, _ := metadata.FromIncomingContext(s.Context())
+		s.interceptor.logMessage(s.Context(), s.method, md, m)
+	}
+
+	return err
+}
+
+// clientStream logs the messages sent on a grpc.ClientStream.
+type clientStream struct {
+	grpc.ClientStream
+	interceptor *Interceptor
+	method      string
+}
+
+// SendMsg logs the message and sends it to the server.
+func (s *clientStream) SendMsg(m interface{}) error {
+	md, _ := metadata.FromOutgoingContext(s.Context())
+	s.interceptor.logMessage(s.Context(), s.method, md, m)
+	return s.ClientStream.SendMsg(m)
+}
+
+// logMessage will log the message fields as parameters.
+func (i *Interceptor) logMessage(ctx context.Context, method string, md metadata.MD, m interface{}) {
+	if !i.checkMethod(method) {
+		return
+	}
+
+	lp := i.Params
+	lp.Request = messageRequest(ctx, method, md, m)
+	if lp.Sink != nil {
+		lp.ToSink(lp.Sink)
+		return
+	}
+	lp.ToLogger(i.logger())
+}
+
+// logger returns the Logger, or a logger dropping the lines if it is not set.
+func (i *Interceptor) logger() *log.Logger {
+	if i.Logger != nil {
+		return i.Logger
+	}
+
+	return discardLogger
+}
+
+// checkMethod checks if the method should be logged.
+func (i *Interceptor) checkMethod(method string) bool {
+	if matchMethod(i.ExcludeMethods, method) {
+		return false
+	}
+
+	if len(i.IncludeMethods) != 0 {
+		return matchMethod(i.IncludeMethods, method)
+	}
+
+	return true
+}
+
+// matchMethod checks if the full method name matches one of the methods or services.
+func matchMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method || (strings.HasSuffix(m, "/") && strings.HasPrefix(method, m)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// messageRequest returns a JSON POST request to the method with the message as the
+// body and the metadata as headers. Messages that are not protobuf messages are
+// sent without a body.
+func messageRequest(ctx context.Context, method string, md metadata.MD, m interface{}) *http.Request {
+	var body []byte
+	if msg, ok := m.(proto.Message); ok {
+		body, _ = protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	}
+
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, method, bytes.NewReader(body))
+	for k, v := range md {
+		r.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	return r
+}
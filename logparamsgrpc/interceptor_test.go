@@ -0,0 +1,173 @@
+package logparamsgrpc
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aaronvb/logparams"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUnaryServerToLogger(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"foo\", \"password\" => \"[FILTERED]\"} Headers: {\"X-Request-Id\" => \"abc123\"}"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	interceptor := &Interceptor{Logger: &logger, Params: logparams.LogParams{LogHeaders: []string{"X-Request-ID"}}}
+	req, _ := structpb.NewStruct(map[string]interface{}{"name": "foo", "password": "bar"})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "abc123"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Create"}
+
+	var handled interface{}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handled = req
+		return nil, nil
+	}
+
+	interceptor.UnaryServer()(ctx, req, info, handler)
+
+	result := strings.TrimSuffix(str.String(), "\n")
+	if result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+	if handled.(*structpb.Struct).Fields["password"].GetStringValue() != "bar" {
+		t.Errorf("Expected request message to be left unfiltered")
+	}
+}
+
+func TestUnaryServerHidePrefixIsEmptyToLogger(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	interceptor := &Interceptor{Logger: &logger, Params: logparams.LogParams{HidePrefix: true}}
+	req, _ := structpb.NewStruct(map[string]interface{}{"name": "foo"})
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Create"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	interceptor.UnaryServer()(context.Background(), req, info, handler)
+	interceptor.UnaryServer()(context.Background(), &structpb.Struct{}, info, handler)
+
+	result := strings.TrimSuffix(str.String(), "\n")
+	if result != "{\"name\" => \"foo\"}" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, "{\"name\" => \"foo\"}")
+	}
+}
+
+func TestUnaryClientExcludeMethodsToLogger(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	interceptor := &Interceptor{
+		Logger:         &logger,
+		IncludeMethods: []string{"/users.Users/"},
+		ExcludeMethods: []string{"/users.Users/Delete"},
+	}
+	req, _ := structpb.NewStruct(map[string]interface{}{"id": "42"})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	for _, method := range []string{"/users.Users/Get", "/users.Users/Delete", "/health.Health/Check"} {
+		interceptor.UnaryClient()(context.Background(), method, req, nil, nil, invoker)
+	}
+
+	result := strings.TrimSuffix(str.String(), "\n")
+	if result != "Parameters: {\"id\" => \"42\"}" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, "Parameters: {\"id\" => \"42\"}")
+	}
+}
+
+func TestUnaryClientWithParsedRequestContextToLogger(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	inbound := httptest.NewRequest("GET", "/?inbound=1", nil)
+	inbound = inbound.WithContext(logparams.NewContext(inbound.Context()))
+	lp := logparams.LogParams{Request: inbound}
+	lp.ToString()
+
+	interceptor := &Interceptor{Logger: &logger}
+	req, _ := structpb.NewStruct(map[string]interface{}{"id": "42"})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	interceptor.UnaryClient()(inbound.Context(), "/users.Users/Get", req, nil, nil, invoker)
+
+	result := strings.TrimSuffix(str.String(), "\n")
+	if result != "Parameters: {\"id\" => \"42\"}" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, "Parameters: {\"id\" => \"42\"}")
+	}
+}
+
+func TestStreamServerToLogger(t *testing.T) {
+	expectedResults := "Parameters: {\"n\" => \"1\"}\nParameters: {\"n\" => \"2\"}\n"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	interceptor := &Interceptor{Logger: &logger}
+	info := &grpc.StreamServerInfo{FullMethod: "/numbers.Numbers/Sum"}
+	stream := &fakeServerStream{ctx: context.Background(), messages: []string{"1", "2"}}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		for {
+			m := &structpb.Struct{}
+			if err := ss.RecvMsg(m); err != nil {
+				return nil
+			}
+		}
+	}
+
+	interceptor.StreamServer()(nil, stream, info, handler)
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	messages []string
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	if len(s.messages) == 0 {
+		return context.Canceled
+	}
+
+	m.(*structpb.Struct).Fields = map[string]*structpb.Value{"n": structpb.NewStringValue(s.messages[0])}
+	s.messages = s.messages[1:]
+	return nil
+}
+
+func TestUnaryServerWithoutLogger(t *testing.T) {
+	interceptor := &Interceptor{}
+	req, _ := structpb.NewStruct(map[string]interface{}{"name": "foo"})
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Create"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor.UnaryServer()(context.Background(), req, info, handler)
+	if err != nil || resp != "ok" {
+		t.Errorf("Expected response was incorrect, got %v, %v, want: %s", resp, err, "ok")
+	}
+}
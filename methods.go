@@ -0,0 +1,66 @@
+package logparams
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxFormBodySize matches the limit http.Request.ParseForm uses for form bodies.
+const maxFormBodySize = 10 << 20 // 10MB
+
+// checkBodyMethod checks if the body should be logged for the request method.
+func (lp *requestParams) checkBodyMethod() bool {
+	if containsMethod(lp.DenyBodyMethods, lp.Request.Method) {
+		return false
+	}
+
+	if len(lp.AllowBodyMethods) != 0 {
+		return containsMethod(lp.AllowBodyMethods, lp.Request.Method)
+	}
+
+	return true
+}
+
+// postForm returns the url encoded form values in the request body.
+// http.Request.ParseForm only reads the body for POST, PUT and PATCH, so for
+// other methods the body is parsed here and put back for the handler.
+func (lp *requestParams) postForm() (url.Values, error) {
+	switch lp.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		err := lp.Request.ParseForm()
+		return lp.Request.PostForm, err
+	}
+
+	if lp.Request.Body == nil || lp.Request.Body == http.NoBody {
+		return url.Values{}, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(lp.Request.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		return url.Values{}, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(lp.Request.Body, maxFormBodySize))
+	lp.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), lp.Request.Body))
+	if err != nil {
+		return url.Values{}, err
+	}
+
+	return url.ParseQuery(string(body))
+}
+
+// containsMethod checks if method is in methods, ignoring case.
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
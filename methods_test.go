@@ -0,0 +1,99 @@
+package logparams
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Request methods
+
+func TestDeleteFormToString(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Form["foo"] != "bar" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Form["foo"], "bar")
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL, "DELETE", "foo=bar", t)
+}
+
+func TestCustomMethodFormBodyIsLeftIntact(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "foo=bar" {
+			t.Errorf("Expected body was incorrect, got %s, want: %s", body, "foo=bar")
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL, "PURGE", "foo=bar", t)
+}
+
+func TestDenyBodyMethodsToString(t *testing.T) {
+	expectedResults := "Parameters: {\"page\" => \"2\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, DenyBodyMethods: []string{"DELETE"}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL+"?page=2", "DELETE", "foo=bar", t)
+}
+
+func TestAllowBodyMethodsToString(t *testing.T) {
+	expectedResults := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, AllowBodyMethods: []string{"POST"}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"foo":"bar"}`)
+	req, _ := http.NewRequest("PUT", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error PUT to httptest server")
+	}
+}
+
+func makeFormRequest(url string, method string, body string, t *testing.T) {
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error %s to httptest server", method)
+	}
+}
@@ -0,0 +1,174 @@
+package logparams
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HandlerNameFunc returns the name of the handler serving the request, used in
+// the Processing line when ShowLifecycle is set.
+type HandlerNameFunc func(r *http.Request) string
+
+// Middleware returns a http middleware that logs the parameters of each request,
+// then the status and duration of the response once the handler has completed.
+// lp is used as the configuration for every request, its Request is ignored.
+// The parameters are cached on the request context, see FromContext.
+//
+// When a Sampler is set, only the parameters and response of sampled requests are
+// logged.
+//
+// When ShowResponseBody is set, JSON response bodies up to MaxResponseBodySize
+// are logged with the same filtering as the request parameters.
+//
+// When ShowLifecycle is set, the Rails style Started and Processing lines are
+// logged before the parameters:
+//
+//	Started POST "/users" for 127.0.0.1 at 2020-03-22 11:15:18 -0700
+//	Processing by UsersHandler
+//	Parameters: {"name" => "foo"}
+//	Completed 201 Created in 14ms
+func Middleware(logger *log.Logger, lp LogParams) func(http.Handler) http.Handler {
+	config := lp.config()
+	return configMiddleware(logger, func(r *http.Request) (*Config, bool) {
+		return config, true
+	})
+}
+
+// RouteMiddleware works like Middleware, with the configuration of each request
+// selected by routes. Requests matching a disabled Route are not logged.
+func RouteMiddleware(logger *log.Logger, routes *Routes) func(http.Handler) http.Handler {
+	return configMiddleware(logger, routes.match)
+}
+
+// configMiddleware returns a http middleware logging each request with the
+// configuration selected by match, see Middleware.
+func configMiddleware(logger *log.Logger, match func(r *http.Request) (*Config, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			config, ok := match(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r = withCache(r)
+			params := config.params(r)
+			start := params.now()
+			if params.ShowLifecycle {
+				logger.Print(params.startedString(start))
+				if params.HandlerName != nil {
+					if name := params.HandlerName(r); name != "" {
+						logger.Printf("Processing by %s", name)
+					}
+				}
+			}
+
+			sampled := params.Sampler.Sample(r, start)
+			var str string
+			if sampled || params.Sampler.alwaysOnError() {
+				str = params.toString()
+			}
+			logParams := str != "" || params.ShowEmpty
+			if sampled && logParams {
+				params.printParams(logger)
+			}
+
+			maxBodySize := 0
+			if params.ShowResponseBody && (sampled || params.Sampler.alwaysOnError()) {
+				maxBodySize = params.maxResponseBodySize()
+			}
+			rw := NewResponseWriter(w, maxBodySize)
+			next.ServeHTTP(rw, r)
+
+			// Unsampled requests that failed are logged once the status is known.
+			if !sampled && params.Sampler.alwaysOnError() && isErrorStatus(rw.Status()) {
+				sampled = true
+				if logParams {
+					params.printParams(logger)
+				}
+			}
+
+			logger.Print(completedString(rw.Status(), params.now().Sub(start)))
+			if !sampled {
+				return
+			}
+
+			if response := params.responseString(rw); response != "" {
+				logger.Print(response)
+			}
+		})
+	}
+}
+
+// now returns the current time from the Clock, or time.Now if it is not set.
+func (c *Config) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+
+	return time.Now()
+}
+
+// startedString will return the Rails style started line of the request, with its
+// query filtered like the query parameters.
+func (lp *requestParams) startedString(t time.Time) string {
+	r := lp.Request
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	return fmt.Sprintf("Started %s \"%s\" for %s at %s", r.Method, lp.filteredURL(r.URL).RequestURI(), remoteIP, t.Format("2006-01-02 15:04:05 -0700"))
+}
+
+// filteredURL returns a copy of u with its query values filtered like the query
+// parameters, e.g. /login?password=[FILTERED].
+func (lp *requestParams) filteredURL(u *url.URL) *url.URL {
+	filtered := *u
+	if u.RawQuery == "" {
+		return &filtered
+	}
+
+	var b strings.Builder
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+
+		filteredValue, ok := lp.filterValue(key, key, value)
+		if !ok {
+			continue
+		}
+		if filteredValue != value {
+			rawValue = queryValueReplacer.Replace(url.QueryEscape(filteredValue))
+		}
+
+		if b.Len() != 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(rawKey)
+		b.WriteByte('=')
+		b.WriteString(rawValue)
+	}
+	filtered.RawQuery = b.String()
+
+	return &filtered
+}
+
+// queryValueReplacer keeps the characters of redacted values readable in a query.
+var queryValueReplacer = strings.NewReplacer("%5B", "[", "%5D", "]", "%2A", "*", "%3A", ":")
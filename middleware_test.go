@@ -0,0 +1,198 @@
+package logparams
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Middleware
+
+func TestMiddlewareToLogger(t *testing.T) {
+	expectedResults := regexp.MustCompile(`^Parameters: \{"foo" => "bar"\}\nCompleted 201 Created in \d+ms\n$`)
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+		fmt.Fprint(rw, "created")
+	})
+
+	server := httptest.NewServer(Middleware(&logger, LogParams{})(handler))
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?foo=bar")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	if !expectedResults.MatchString(str.String()) {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestMiddlewareResponseBodyToLogger(t *testing.T) {
+	expectedResults := "Response: {\"id\" => \"1\", \"password\" => \"[FILTERED]\"}"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"id":"1","password":"foobar"}`)
+	})
+
+	server := httptest.NewServer(Middleware(&logger, LogParams{ShowResponseBody: true})(handler))
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(str.String(), "\n"), "\n")
+	if !strings.HasPrefix(lines[0], "Completed 200 OK in ") {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lines[0], "Completed 200 OK in ...")
+	}
+	if len(lines) != 2 || lines[1] != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestMiddlewareResponseBodyOverLimitToLogger(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"id":"1","name":"a long name"}`)
+	})
+
+	server := httptest.NewServer(Middleware(&logger, LogParams{ShowResponseBody: true, MaxResponseBodySize: 8})(handler))
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	if strings.Contains(str.String(), "Response:") {
+		t.Errorf("Expected response body not to be logged, got %s", str.String())
+	}
+}
+
+func TestResponseWriterRecordsStatusAndSize(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder, DefaultMaxResponseBodySize)
+	rw.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(rw, "hello")
+	rw.WriteHeader(http.StatusInternalServerError)
+
+	if rw.Status() != http.StatusOK {
+		t.Errorf("Expected status was incorrect, got %d, want: %d", rw.Status(), http.StatusOK)
+	}
+	if rw.Size() != 5 {
+		t.Errorf("Expected size was incorrect, got %d, want: %d", rw.Size(), 5)
+	}
+	if rw.Body() != nil {
+		t.Errorf("Expected body not to be captured for text/plain, got %s", rw.Body())
+	}
+}
+
+func TestMiddlewareInformationalStatusToLogger(t *testing.T) {
+	expectedResults := regexp.MustCompile(`^Parameters: \{"foo" => "bar"\}\nCompleted 404 Not Found in \d+ms\n$`)
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		rw.WriteHeader(http.StatusEarlyHints)
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	server := httptest.NewServer(Middleware(&logger, LogParams{})(handler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?foo=bar")
+	if err != nil {
+		t.Fatalf("Error GET to httptest server")
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status was incorrect, got %d, want: %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if !expectedResults.MatchString(str.String()) {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestMiddlewareLifecycleToLogger(t *testing.T) {
+	expectedResults := "Started POST \"/users?page=1\" for 127.0.0.1 at 2020-03-22 11:15:18 +0000\n" +
+		"Processing by UsersHandler#create\n" +
+		"Parameters: {\"name\" => \"foo\"}\n" +
+		"Completed 201 Created in 14ms\n"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	now := time.Date(2020, 3, 22, 11, 15, 18, 0, time.UTC)
+	clock := func() time.Time {
+		t := now
+		now = now.Add(14 * time.Millisecond)
+		return t
+	}
+	handlerName := func(r *http.Request) string {
+		return "UsersHandler#create"
+	}
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+	lp := LogParams{ShowLifecycle: true, HandlerName: handlerName, Clock: clock}
+
+	req := httptest.NewRequest("POST", "/users?page=1", strings.NewReader("name=foo"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "127.0.0.1:52114"
+	Middleware(&logger, lp)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestMiddlewareLifecycleFiltersQuery(t *testing.T) {
+	expectedResult := "Started GET \"/login?password=[FILTERED]&page=1\" for 127.0.0.1 at 2020-03-22 11:15:18 +0000\n"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	clock := func() time.Time {
+		return time.Date(2020, 3, 22, 11, 15, 18, 0, time.UTC)
+	}
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	lp := LogParams{ShowLifecycle: true, Clock: clock, Redact: []RedactRule{{Key: "token", Redaction: Remove}}}
+
+	req := httptest.NewRequest("GET", "/login?password=hunter2&token=abc&page=1", nil)
+	req.RemoteAddr = "127.0.0.1:52114"
+	Middleware(&logger, lp)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if started, _, _ := strings.Cut(str.String(), "\n"); started+"\n" != expectedResult {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", started, expectedResult)
+	}
+}
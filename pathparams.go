@@ -0,0 +1,85 @@
+package logparams
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// PathParamsFunc returns the path parameters captured by the router for the request.
+// gorilla/mux's mux.Vars can be used as is.
+type PathParamsFunc func(r *http.Request) map[string]string
+
+// PathValues returns a PathParamsFunc for the Go 1.22 http.ServeMux, which reads
+// the named wildcards of the matched pattern with Request.PathValue.
+func PathValues(names ...string) PathParamsFunc {
+	return URLParams(func(r *http.Request, name string) string {
+		return r.PathValue(name)
+	}, names...)
+}
+
+// URLParams returns a PathParamsFunc for routers that look up path parameters
+// one name at a time, such as chi's chi.URLParam.
+func URLParams(param func(r *http.Request, name string) string, names ...string) PathParamsFunc {
+	return func(r *http.Request) map[string]string {
+		params := make(map[string]string, len(names))
+		for _, name := range names {
+			if value := param(r, name); value != "" {
+				params[name] = value
+			}
+		}
+
+		return params
+	}
+}
+
+// parsePathParams will call the PathParams func and return a string of path parameters,
+// filtered like the other parameters.
+func (lp *requestParams) parsePathParams() (string, map[string]string) {
+	if lp.PathParams == nil {
+		return "", nil
+	}
+
+	params := lp.PathParams(lp.Request)
+	if len(params) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	pathParams := make(map[string]string, len(params))
+	for _, name := range names {
+		value, ok := lp.filterValue(name, name, params[name])
+		if !ok {
+			continue
+		}
+
+		key := toValidUTF8(name)
+		writePair(buf, len(pathParams) == 0, key, value)
+		pathParams[key] = value
+	}
+
+	return buf.String(), pathParams
+}
+
+// mergePathParams will put the path parameters in front of the other parameters,
+// the way Rails shows route parameters. A JSON array body is nested under "_json".
+func mergePathParams(pathString string, paramsString string) string {
+	switch {
+	case pathString == "":
+		return paramsString
+	case paramsString == "" || paramsString == "{}":
+		return fmt.Sprintf("{%s}", pathString)
+	case strings.HasPrefix(paramsString, "{"):
+		return fmt.Sprintf("{%s, %s", pathString, paramsString[1:])
+	}
+
+	return fmt.Sprintf("{%s, \"_json\" => %s}", pathString, paramsString)
+}
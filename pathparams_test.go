@@ -0,0 +1,122 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Path parameters
+
+func TestServeMuxPathParamsToString(t *testing.T) {
+	expectedResults := "Parameters: {\"id\" => \"42\", \"foo\" => \"bar\"}"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, PathParams: PathValues("id")}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Path["id"] != "42" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Path["id"], "42")
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "/users/42?foo=bar")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestPathParamsAreFiltered(t *testing.T) {
+	req := httptest.NewRequest("POST", "/reset/s3cret/4242424242424242/abcdefgh", nil)
+	req.SetPathValue("token", "s3cret")
+	req.SetPathValue("card", "4242424242424242")
+	req.SetPathValue("slug", "abcdefgh")
+	pathParams := PathValues("token", "card", "slug")
+
+	tests := []struct {
+		lp       LogParams
+		expected string
+	}{
+		{
+			LogParams{Redact: []RedactRule{{Key: "token", Redaction: Remove}, {Key: "card", Redaction: PartialMask}}},
+			"Parameters: {\"card\" => \"************4242\", \"slug\" => \"abcdefgh\"}",
+		},
+		{
+			LogParams{Allowlist: true, AllowKeys: []string{"slug"}},
+			"Parameters: {\"card\" => \"[FILTERED]\", \"slug\" => \"abcdefgh\", \"token\" => \"[FILTERED]\"}",
+		},
+		{
+			LogParams{MaxValueLength: 4},
+			"Parameters: {\"card\" => \"4242…(truncated 12 bytes)\", \"slug\" => \"abcd…(truncated 4 bytes)\", \"token\" => \"s3cr…(truncated 2 bytes)\"}",
+		},
+	}
+
+	for _, test := range tests {
+		test.lp.Request = req
+		test.lp.PathParams = pathParams
+		if result := test.lp.ToString(); result != test.expected {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", result, test.expected)
+		}
+	}
+}
+
+func TestVarsPathParamsOnlyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"id\" => \"42\", \"org\" => \"acme\"}"
+
+	vars := func(r *http.Request) map[string]string {
+		return map[string]string{"org": "acme", "id": "42"}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, PathParams: vars}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestURLParamsWithJSONArrayBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"id\" => \"42\", \"_json\" => [{\"foo\" => \"bar\"}]}"
+
+	urlParam := func(r *http.Request, name string) string {
+		if name == "id" {
+			return "42"
+		}
+		return ""
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, PathParams: URLParams(urlParam, "id", "missing")}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if _, ok := lp.ToFields().Path["missing"]; ok {
+			t.Errorf("Expected missing path param to be left out")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`[{"foo":"bar"}]`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
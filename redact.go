@@ -0,0 +1,111 @@
+package logparams
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Redaction is how the value of a redacted parameter is logged.
+type Redaction int
+
+const (
+	// Mask replaces the value with [FILTERED].
+	Mask Redaction = iota
+	// PartialMask keeps the last 4 characters of values longer than 8 characters,
+	// e.g. ************4242, and masks shorter values.
+	PartialMask
+	// Remove leaves the parameter out.
+	Remove
+	// HMAC replaces the value with a keyed digest, e.g. [HMAC:3f9a0c1d2e4b5a69], so
+	// equal values can be matched across requests without logging them. The value
+	// is masked if HMACKey is not set.
+	HMAC
+)
+
+// RedactRule redacts the values of a parameter key in forms, query strings and
+// JSON objects at any depth, or of the parameter at a path.
+type RedactRule struct {
+	Key string
+	// Path matches a form or query key, or the dot separated keys of a JSON value,
+	// e.g. "user.email", instead of Key.
+	Path      string
+	Redaction Redaction
+}
+
+// passwordKeys are masked unless ShowPassword is set.
+var passwordKeys = []string{"password", "password_confirmation"}
+
+// redactRule returns the redaction of the key at path, or false if it is not redacted.
+// Rules in Redact take precedence over the BodyStruct and Spec rules and the password filter.
+func (lp *requestParams) redactRule(key string, path string) (Redaction, bool) {
+	for _, rule := range lp.Redact {
+		if rule.match(key, path) {
+			return rule.Redaction, true
+		}
+	}
+
+	for _, rule := range lp.structRules().redact {
+		if rule.matchFold(key, path) {
+			return rule.Redaction, true
+		}
+	}
+
+	for _, rule := range lp.specRules {
+		if rule.match(key, path) {
+			return rule.Redaction, true
+		}
+	}
+
+	if !lp.ShowPassword {
+		for _, passwordKey := range passwordKeys {
+			if passwordKey == key {
+				return Mask, true
+			}
+		}
+	}
+
+	return Mask, false
+}
+
+// match checks if the rule matches the key at path.
+func (rule *RedactRule) match(key string, path string) bool {
+	if rule.Path != "" {
+		return rule.Path == path
+	}
+
+	return rule.Key == key
+}
+
+// matchFold checks if the rule matches the key at path ignoring case, the way
+// encoding/json matches object keys to struct fields.
+func (rule *RedactRule) matchFold(key string, path string) bool {
+	if rule.Path != "" {
+		return strings.EqualFold(rule.Path, path)
+	}
+
+	return strings.EqualFold(rule.Key, key)
+}
+
+// redactValue will return the value redacted.
+func (lp *requestParams) redactValue(redaction Redaction, value string) string {
+	switch redaction {
+	case PartialMask:
+		runes := []rune(toValidUTF8(value))
+		if len(runes) <= 8 {
+			return "[FILTERED]"
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	case HMAC:
+		if len(lp.HMACKey) == 0 {
+			return "[FILTERED]"
+		}
+		mac := hmac.New(sha256.New, lp.HMACKey)
+		mac.Write([]byte(value))
+		return fmt.Sprintf("[HMAC:%s]", hex.EncodeToString(mac.Sum(nil)[:8]))
+	}
+
+	return "[FILTERED]"
+}
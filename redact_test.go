@@ -0,0 +1,84 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Redaction
+
+func TestRedactJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"card\" => \"************4242\", \"email\" => \"[HMAC:2b1a1066f2966953]\", \"user\" => {\"email\" => \"[HMAC:2b1a1066f2966953]\", \"password\" => \"[FILTERED]\"}}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{
+			Request: r,
+			Redact: []RedactRule{
+				{Key: "email", Redaction: HMAC},
+				{Key: "card", Redaction: PartialMask},
+				{Key: "ssn", Redaction: Remove},
+			},
+			HMACKey: []byte("secret"),
+		}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if _, ok := lp.ToFields().Json["ssn"]; ok {
+			t.Errorf("Expected removed key to be left out of fields")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"email":"foo@example.com","card":"4242424242424242","ssn":"123-45-6789","user":{"email":"foo@example.com","password":"bar"}}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestRedactFormToString(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"foo\", \"password\" => \"[HMAC:2b1a1066f2966953]\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{
+			Request: r,
+			Redact: []RedactRule{
+				{Key: "password", Redaction: HMAC},
+				{Key: "token", Redaction: Remove},
+			},
+			HMACKey: []byte("secret"),
+		}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL, "POST", "name=foo&password=foo%40example.com&token=abc", t)
+}
+
+func TestRedactHMACWithoutKeyIsMasked(t *testing.T) {
+	expectedResults := "Parameters: {\"email\" => \"[FILTERED]\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Redact: []RedactRule{{Key: "email", Redaction: HMAC}}}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?email=foo%40example.com")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
@@ -0,0 +1,220 @@
+package logparams
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxPooledBufferSize keeps buffers grown by very large bodies out of the pool.
+const maxPooledBufferSize = 64 << 10 // 64KB
+
+// bufferPool holds the buffers parameters are rendered into.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// writePair will write a "key" => "value" pair, separated from the previous pair
+// unless it is the first. The key and value are escaped with writeEscaped.
+func writePair(buf *bytes.Buffer, first bool, key string, value string) {
+	if !first {
+		buf.WriteString(", ")
+	}
+
+	buf.WriteByte('"')
+	writeEscaped(buf, key)
+	buf.WriteString(`" => "`)
+	writeEscaped(buf, value)
+	buf.WriteByte('"')
+}
+
+// writeEscaped will write s with backslashes, quotes, control characters and line
+// separators escaped, so a logged parameter can't forge log lines or send terminal
+// escape sequences, e.g. a line feed is written as \n and ESC as \x1b.
+func writeEscaped(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < 0x7f && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+
+		if c < utf8.RuneSelf {
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\x`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		// C1 controls include the single byte CSI, and some log viewers break lines
+		// on U+2028 and U+2029.
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r >= 0x80 && r <= 0x9f) || r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u`)
+			buf.WriteByte(hex[r>>12&0xf])
+			buf.WriteByte(hex[r>>8&0xf])
+			buf.WriteByte(hex[r>>4&0xf])
+			buf.WriteByte(hex[r&0xf])
+			start = i + size
+		}
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+}
+
+// writeJSONKey will write the key of a JSON object value, separated from the
+// previous value unless it is the first.
+func writeJSONKey(buf *bytes.Buffer, first bool, key string) {
+	if !first {
+		buf.WriteString(", ")
+	}
+
+	writeJSONString(buf, key)
+	buf.WriteString(" => ")
+}
+
+// writeJSONScalar will write a decoded JSON number, bool or null.
+func writeJSONScalar(buf *bytes.Buffer, v interface{}) {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case float64:
+		writeJSONNumber(buf, value)
+	case string:
+		writeJSONString(buf, value)
+	default:
+		b, _ := marshalJSON(value)
+		buf.Write(b)
+	}
+}
+
+// writeJSONNumber will write f the way encoding/json does.
+func writeJSONNumber(buf *bytes.Buffer, f float64) {
+	var scratch [64]byte
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	b := strconv.AppendFloat(scratch[:0], f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	buf.Write(b)
+}
+
+// writeJSONString will write s as a quoted JSON string, escaping it the way
+// encoding/json does without escaping HTML characters, and escaping C1 controls.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString("\uFFFD")
+			i += size
+			start = i
+			continue
+		}
+
+		// U+2028 and U+2029 are escaped so the output is valid JavaScript, and C1
+		// controls so a value can't send the single byte CSI to a terminal.
+		if (r >= 0x80 && r <= 0x9f) || r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u`)
+			buf.WriteByte(hex[r>>12&0xf])
+			buf.WriteByte(hex[r>>8&0xf])
+			buf.WriteByte(hex[r>>4&0xf])
+			buf.WriteByte(hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
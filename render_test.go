@@ -0,0 +1,148 @@
+package logparams
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Rendering
+
+func TestWriteJSONStringMatchesEncodingJSON(t *testing.T) {
+	values := []string{
+		"plain",
+		`quote " and backslash \`,
+		"control \b\f\n\r\t\x00\x1f",
+		"<html> & unicode é 日本",
+		"line   paragraph  ",
+		"invalid \xff utf-8",
+		"",
+	}
+
+	for _, value := range values {
+		var buf bytes.Buffer
+		writeJSONString(&buf, value)
+		expected, _ := marshalJSON(value)
+		if buf.String() != string(expected) {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", buf.String(), expected)
+		}
+	}
+}
+
+func TestWriteJSONNumberMatchesEncodingJSON(t *testing.T) {
+	numbers := []float64{0, 1, -42, 3.14159, 1e20, 1e21, 1.5e-7, 0.000001, -2.5e-9, 123456789012}
+
+	for _, number := range numbers {
+		var buf bytes.Buffer
+		writeJSONNumber(&buf, number)
+		expected, _ := marshalJSON(number)
+		if buf.String() != string(expected) {
+			t.Errorf("Expected number was incorrect, got %s, want: %s", buf.String(), expected)
+		}
+	}
+}
+
+func TestJSONBodyNumbersAndArraysToString(t *testing.T) {
+	expectedResults := "Parameters: {\"active\" => true, \"ids\" => [1, 2.5], \"note\" => null, \"user\" => {\"name\" => \"foo\", \"tags\" => [\"a\", \"b\"]}, \"users\" => [{\"id\" => 1}, {\"id\" => 2}]}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	body := `{"user":{"name":"foo","tags":["a","b"]},"ids":[1,2.5],"active":true,"note":null,"users":[{"id":1},{"id":2}]}`
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestJSONBodyRemovedValuesToString(t *testing.T) {
+	expectedResults := "Parameters: {\"list\" => [\"a\", \"b\"], \"user\" => {\"name\" => \"foo\"}}"
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"user":{"card":"4242","name":"foo"},"list":["a","b"],"card":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	lp := LogParams{Request: req, Redact: []RedactRule{{Key: "card", Redaction: Remove}}}
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+}
+
+func TestWriteEscaped(t *testing.T) {
+	values := map[string]string{
+		"plain text":            "plain text",
+		"line\nbreak\r\n":       `line\nbreak\r\n`,
+		"\x1b[31mred\x1b[0m":    `\x1b[31mred\x1b[0m`,
+		"tab\tdel\x7f":          `tab\tdel\x7f`,
+		`quote " backslash \`:   `quote \" backslash \\`,
+		"csi \u009b31m":         `csi \u009b31m`,
+		"separator \u2028 é 日本": `separator \u2028 é 日本`,
+	}
+
+	for value, expected := range values {
+		var buf bytes.Buffer
+		writeEscaped(&buf, value)
+		if buf.String() != expected {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", buf.String(), expected)
+		}
+	}
+}
+
+func TestToLoggerFormatString(t *testing.T) {
+	expectedResults := "Parameters: {\"q\" => \"%s%d%!\"}\n"
+
+	var str bytes.Buffer
+	logger := log.New(&str, "", 0)
+
+	lp := LogParams{Request: httptest.NewRequest("GET", "/?q=%25s%25d%25!", nil)}
+	lp.ToLogger(logger)
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestLogInjectionIsEscaped(t *testing.T) {
+	expectedResults := "Parameters: {\"name\\r\\nINFO\" => \"foo\\nINFO 2020/03/22 11:15:18 forged\", \"theme\" => \"\\x1b[31mred\"}\n"
+
+	var str bytes.Buffer
+	logger := log.New(&str, "", 0)
+
+	form := url.Values{"name\r\nINFO": {"foo\nINFO 2020/03/22 11:15:18 forged"}, "theme": {"\x1b[31mred"}}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	lp := LogParams{Request: req}
+	lp.ToLogger(logger)
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+
+	expectedResults = "Parameters: {\"a\" => \"x\\u009b31m\", \"b\" => \"\\u001b[31m\\u2028\"}\n"
+	for _, streamJSON := range []bool{false, true} {
+		str.Reset()
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":"x\u009b31m","b":"\u001b[31m\u2028"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		lp := LogParams{Request: req, StreamJSON: streamJSON}
+		lp.ToLogger(logger)
+
+		if str.String() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package logparams
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxResponseBodySize is the captured response body limit used when
+// MaxResponseBodySize is not set.
+const DefaultMaxResponseBodySize = 64 << 10 // 64KB
+
+// ResponseWriter wraps a http.ResponseWriter to record the status, size and, for
+// JSON responses, the body written by the handler.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	body        bytes.Buffer
+	maxBodySize int
+	captureBody bool
+	truncated   bool
+	wroteHeader bool
+}
+
+// NewResponseWriter returns a ResponseWriter that captures up to maxBodySize bytes
+// of a JSON response body. A maxBodySize of 0 will not capture the body.
+func NewResponseWriter(w http.ResponseWriter, maxBodySize int) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, maxBodySize: maxBodySize}
+}
+
+// WriteHeader records the status code and sends it to the wrapped ResponseWriter.
+// Informational statuses, such as 103 Early Hints, are sent without being recorded
+// so the handler can still send the final status.
+func (rw *ResponseWriter) WriteHeader(status int) {
+	if rw.wroteHeader {
+		return
+	}
+
+	if status >= 100 && status <= 199 && status != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	rw.status = status
+	rw.wroteHeader = true
+	rw.captureBody = rw.maxBodySize > 0 && isJSONContentType(rw.Header().Get("Content-Type"))
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the size and body of the response and writes it to the wrapped
+// ResponseWriter.
+func (rw *ResponseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += n
+
+	if rw.captureBody && !rw.truncated {
+		if rw.body.Len()+n > rw.maxBodySize {
+			rw.truncated = true
+			rw.body.Reset()
+		} else {
+			rw.body.Write(b[:n])
+		}
+	}
+
+	return n, err
+}
+
+// Flush sends any buffered data to the client if the wrapped ResponseWriter supports it.
+func (rw *ResponseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the wrapped ResponseWriter supports it.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("logparams: wrapped ResponseWriter does not implement http.Hijacker")
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Status returns the response status code, 200 if the handler did not set one.
+func (rw *ResponseWriter) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
+
+// Size returns the number of body bytes written.
+func (rw *ResponseWriter) Size() int {
+	return rw.size
+}
+
+// Body returns the captured JSON response body, or nil if it was not captured or
+// was larger than the limit.
+func (rw *ResponseWriter) Body() []byte {
+	if !rw.captureBody || rw.truncated {
+		return nil
+	}
+
+	return rw.body.Bytes()
+}
+
+// completedString will return the Rails style completed line of the response.
+func completedString(status int, duration time.Duration) string {
+	return fmt.Sprintf("Completed %d %s in %dms", status, http.StatusText(status), duration.Milliseconds())
+}
+
+// responseString will return a string of the filtered JSON response body.
+func (lp *requestParams) responseString(rw *ResponseWriter) string {
+	return lp.renderResponseBody(rw.Header().Get("Content-Encoding"), rw.Body())
+}
+
+// renderResponseBody will decompress and return a string of the filtered JSON body.
+func (lp *requestParams) renderResponseBody(contentEncoding string, body []byte) string {
+	body, err := decompressBody(contentEncoding, body, lp.maxDecompressedSize())
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+
+	var str string
+	if lp.StreamJSON {
+		str, _ = lp.streamJSON(bytes.NewReader(body))
+	} else {
+		str, _ = lp.renderJSON(body)
+	}
+	if str == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("Response: %s", str)
+}
+
+// maxResponseBodySize returns the configured response body capture limit.
+func (lp *requestParams) maxResponseBodySize() int {
+	if lp.MaxResponseBodySize > 0 {
+		return lp.MaxResponseBodySize
+	}
+
+	return DefaultMaxResponseBodySize
+}
+
+// isJSONContentType checks for application/json in the content type.
+func isJSONContentType(contentType string) bool {
+	return strings.Contains(contentType, "application/json")
+}
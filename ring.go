@@ -0,0 +1,51 @@
+package logparams
+
+import "sync"
+
+// DefaultRingBufferSize is the number of entries a RingBufferSink keeps when its
+// size is not set.
+const DefaultRingBufferSize = 100
+
+// RingBufferSink keeps the last entries in memory, e.g. for a debug endpoint
+// showing recent requests, or for tests.
+type RingBufferSink struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRingBufferSink returns a RingBufferSink keeping the last size entries.
+func NewRingBufferSink(size int) *RingBufferSink {
+	if size <= 0 {
+		size = DefaultRingBufferSize
+	}
+
+	return &RingBufferSink{entries: make([]Entry, size)}
+}
+
+// Log keeps the entry, replacing the oldest one when the buffer is full.
+func (s *RingBufferSink) Log(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+
+	return nil
+}
+
+// Entries returns the kept entries, oldest first.
+func (s *RingBufferSink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]Entry(nil), s.entries[:s.next]...)
+	}
+
+	entries := make([]Entry, 0, len(s.entries))
+	entries = append(entries, s.entries[s.next:]...)
+	return append(entries, s.entries[:s.next]...)
+}
@@ -0,0 +1,132 @@
+package logparams
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultMaxFileSize is the size a RotatingFileSink file reaches before it is
+// rotated when MaxSize is not set.
+const DefaultMaxFileSize = 100 << 20 // 100MB
+
+// DefaultMaxBackups is the number of rotated files kept when MaxBackups is not set.
+const DefaultMaxBackups = 3
+
+// RotatingFileSink writes the parameters of each entry to a file as a line. When
+// the file would grow past MaxSize, it is renamed with a .1 suffix, older files are
+// shifted to .2, .3 and so on, and a new file is started.
+//
+//	sink := &logparams.RotatingFileSink{Filename: "/var/log/app/params.log", MaxSize: 10 << 20}
+//	defer sink.Close()
+type RotatingFileSink struct {
+	// Filename is the file written to, created if it doesn't exist.
+	Filename string
+	// MaxSize is the size in bytes a file reaches before it is rotated (default 100MB).
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept (default 3).
+	MaxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Log writes the entry, rotating the file first if it would grow past MaxSize.
+func (s *RotatingFileSink) Log(entry Entry) error {
+	line := entry.String + "\n"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize() {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.WriteString(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close closes the file.
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open opens the file for appending.
+func (s *RotatingFileSink) open() error {
+	file, err := os.OpenFile(s.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups, moves the file to the first backup and opens a new file.
+func (s *RotatingFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	maxBackups := s.maxBackups()
+	os.Remove(backupName(s.Filename, maxBackups))
+	for i := maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupName(s.Filename, i), backupName(s.Filename, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(s.Filename, backupName(s.Filename, 1)); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// maxSize returns the configured file size limit.
+func (s *RotatingFileSink) maxSize() int64 {
+	if s.MaxSize > 0 {
+		return s.MaxSize
+	}
+
+	return DefaultMaxFileSize
+}
+
+// maxBackups returns the configured number of rotated files kept.
+func (s *RotatingFileSink) maxBackups() int {
+	if s.MaxBackups > 0 {
+		return s.MaxBackups
+	}
+
+	return DefaultMaxBackups
+}
+
+// backupName returns the name of the nth rotated file.
+func backupName(filename string, n int) string {
+	return fmt.Sprintf("%s.%d", filename, n)
+}
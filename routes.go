@@ -0,0 +1,112 @@
+package logparams
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Route is a rule that selects the LogParams profile for matching requests.
+// Every matcher that is set must match, a Route without matchers matches all requests.
+type Route struct {
+	// Prefix matches paths starting with it, e.g. "/payments/".
+	Prefix string
+	// Glob matches paths with path.Match, e.g. "/users/*/avatar".
+	Glob string
+	// Regexp matches paths with the regular expression.
+	Regexp *regexp.Regexp
+	// Methods matches these HTTP methods (default all).
+	Methods []string
+	// Options are applied to Default for matching requests, e.g. to add stricter
+	// redaction on top of the normal profile.
+	Options []Option
+	// Params replaces Default for matching requests when Options is empty, its
+	// Request is ignored.
+	Params LogParams
+	// Disable will not log matching requests.
+	Disable bool
+}
+
+// Routes selects the LogParams profile of a request with the first matching Route.
+//
+//	routes := &logparams.Routes{
+//		Rules: []logparams.Route{
+//			{Prefix: "/healthz", Disable: true},
+//			{Prefix: "/uploads/", Methods: []string{"POST"}, Disable: true},
+//			{Glob: "/payments/*", Options: []logparams.Option{logparams.WithRedact(cardRule)}},
+//			{Prefix: "/admin/", Params: logparams.LogParams{DenyBodyMethods: []string{"POST"}}},
+//		},
+//		Default: logparams.LogParams{},
+//	}
+type Routes struct {
+	Rules []Route
+	// Default is the profile used when no Route matches.
+	Default LogParams
+}
+
+// Match returns the profile for the request with Request set, or false if logging
+// is disabled for the request.
+func (rs *Routes) Match(r *http.Request) (LogParams, bool) {
+	config, ok := rs.match(r)
+	if !ok {
+		return LogParams{}, false
+	}
+
+	return config.logParams(r), true
+}
+
+// match returns the configuration for the request, or false if logging is disabled
+// for the request.
+func (rs *Routes) match(r *http.Request) (*Config, bool) {
+	for _, route := range rs.Rules {
+		if route.match(r) {
+			if route.Disable {
+				return nil, false
+			}
+			return route.config(&rs.Default), true
+		}
+	}
+
+	return rs.Default.config(), true
+}
+
+// config returns the configuration of the route, Default with the Options applied,
+// or Params if there are none.
+func (route *Route) config(defaultParams *LogParams) *Config {
+	if len(route.Options) == 0 {
+		return route.Params.config()
+	}
+
+	config := defaultParams.config()
+	for _, option := range route.Options {
+		option(config)
+	}
+
+	return config
+}
+
+// match checks if the request matches every matcher of the route.
+func (route *Route) match(r *http.Request) bool {
+	if len(route.Methods) != 0 && !containsMethod(route.Methods, r.Method) {
+		return false
+	}
+
+	urlPath := r.URL.Path
+	if route.Prefix != "" && !strings.HasPrefix(urlPath, route.Prefix) {
+		return false
+	}
+
+	if route.Glob != "" {
+		matched, err := path.Match(route.Glob, urlPath)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if route.Regexp != nil && !route.Regexp.MatchString(urlPath) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,126 @@
+package logparams
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Routes
+
+func TestRoutesMatch(t *testing.T) {
+	routes := &Routes{
+		Rules: []Route{
+			{Prefix: "/healthz", Disable: true},
+			{Prefix: "/uploads/", Methods: []string{"POST"}, Disable: true},
+			{Glob: "/payments/*", Params: LogParams{HidePrefix: true}},
+			{Regexp: regexp.MustCompile(`^/users/\d+$`), Params: LogParams{ShowEmpty: true}},
+		},
+		Default: LogParams{ShowCookies: true},
+	}
+
+	tests := []struct {
+		method   string
+		target   string
+		enabled  bool
+		expected func(lp LogParams) bool
+	}{
+		{"GET", "/healthz", false, nil},
+		{"POST", "/uploads/avatar", false, nil},
+		{"GET", "/uploads/avatar", true, func(lp LogParams) bool { return lp.ShowCookies }},
+		{"POST", "/payments/42", true, func(lp LogParams) bool { return lp.HidePrefix && !lp.ShowCookies }},
+		{"POST", "/payments/42/refund", true, func(lp LogParams) bool { return lp.ShowCookies }},
+		{"GET", "/users/42", true, func(lp LogParams) bool { return lp.ShowEmpty }},
+		{"GET", "/users/me", true, func(lp LogParams) bool { return lp.ShowCookies }},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.target, nil)
+		lp, enabled := routes.Match(r)
+		if enabled != test.enabled {
+			t.Errorf("Expected %s %s enabled to be %t, got %t", test.method, test.target, test.enabled, enabled)
+			continue
+		}
+		if enabled && (lp.Request != r || !test.expected(lp)) {
+			t.Errorf("Expected profile was incorrect for %s %s, got %+v", test.method, test.target, lp)
+		}
+	}
+}
+
+func TestRouteOptionsOverlayDefault(t *testing.T) {
+	sink := NewRingBufferSink(10)
+	routes := &Routes{
+		Rules: []Route{
+			{Prefix: "/payments/", Options: []Option{WithRedact(RedactRule{Key: "card", Redaction: PartialMask})}},
+		},
+		Default: LogParams{
+			Sink:       sink,
+			LogHeaders: []string{"X-Request-Id"},
+			Redact:     make([]RedactRule, 1, 2),
+		},
+	}
+	routes.Default.Redact[0] = RedactRule{Key: "ssn", Redaction: Remove}
+
+	lp, _ := routes.Match(httptest.NewRequest("POST", "/payments/42?card=4242424242424242&ssn=123", nil))
+	if lp.Sink != sink || len(lp.LogHeaders) != 1 || len(lp.Redact) != 2 {
+		t.Errorf("Expected profile to overlay Default, got %+v", lp)
+	}
+	if len(routes.Default.Redact[:2][1].Key) != 0 {
+		t.Errorf("Expected Default not to be changed, got %+v", routes.Default.Redact[:2])
+	}
+
+	expected := `Parameters: {"card" => "************4242"}`
+	if result := lp.ToString(); result != expected {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expected)
+	}
+
+	lp, _ = routes.Match(httptest.NewRequest("POST", "/users?card=4242424242424242", nil))
+	if len(lp.Redact) != 1 {
+		t.Errorf("Expected Default profile, got %+v", lp)
+	}
+}
+
+func TestRouteParamsReplaceDefault(t *testing.T) {
+	routes := &Routes{
+		Rules:   []Route{{Prefix: "/payments/", Params: LogParams{HidePrefix: true}}},
+		Default: LogParams{Sink: NewRingBufferSink(10)},
+	}
+
+	lp, _ := routes.Match(httptest.NewRequest("GET", "/payments/42", nil))
+	if lp.Sink != nil || !lp.HidePrefix {
+		t.Errorf("Expected Params to replace Default, got %+v", lp)
+	}
+}
+
+func TestRouteMiddlewareToLogger(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	routes := &Routes{
+		Rules: []Route{
+			{Prefix: "/healthz", Disable: true},
+			{Prefix: "/payments/", Params: LogParams{HidePrefix: true}},
+		},
+	}
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(RouteMiddleware(&logger, routes)(handler))
+	defer server.Close()
+
+	for _, target := range []string{"/healthz?foo=bar", "/payments/42?foo=bar"} {
+		_, err := http.Get(server.URL + target)
+		if err != nil {
+			t.Errorf("Error GET to httptest server")
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(str.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "{\"foo\" => \"bar\"}" || !strings.HasPrefix(lines[1], "Completed 200 OK") {
+		t.Errorf("Expected string was incorrect, got %s", str.String())
+	}
+}
@@ -0,0 +1,124 @@
+package logparams
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRequestIDHeader is the header sampling is keyed by when RequestIDHeader
+// is not set.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// Sampler decides which requests have their parameters and response logged by
+// Middleware. Set it per route with Routes to sample hot endpoints at their own
+// rate. A Sampler keeps the state of its rate limit, so it must be shared by
+// pointer.
+//
+// Requests are sampled by the hash of their request ID, so the same request ID is
+// always sampled the same way. Requests without an ID are sampled at random.
+type Sampler struct {
+	// Rate is the fraction of requests logged, e.g. 0.01 for 1%. 0 logs every request.
+	Rate float64
+	// Limit is the maximum number of requests logged per second. 0 is unlimited.
+	Limit float64
+	// Burst is the number of requests that can be logged at once above Limit (default 1).
+	Burst int
+	// AlwaysOnError logs requests that complete with a 4xx or 5xx status, even when
+	// they are not sampled. Their parameters are parsed before the handler runs and
+	// logged once it has completed.
+	AlwaysOnError bool
+	// RequestIDHeader is the header holding the request ID (default X-Request-Id).
+	RequestIDHeader string
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// Sample checks if the request should be logged at time now, taking a token from
+// the rate limit if it is.
+func (s *Sampler) Sample(r *http.Request, now time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	return s.sampled(r) && s.allow(now)
+}
+
+// sampled checks if the request falls within the sample rate.
+func (s *Sampler) sampled(r *http.Request) bool {
+	if s.Rate <= 0 || s.Rate >= 1 {
+		return true
+	}
+
+	header := s.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+
+	id := r.Header.Get(header)
+	if id == "" {
+		return rand.Float64() < s.Rate
+	}
+
+	return hashFraction(id) < s.Rate
+}
+
+// allow takes a token from the rate limit bucket, refilled at Limit tokens per
+// second up to Burst, or returns false if it is empty.
+func (s *Sampler) allow(now time.Time) bool {
+	if s.Limit <= 0 {
+		return true
+	}
+
+	burst := float64(s.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last.IsZero() {
+		s.tokens = burst
+	} else if elapsed := now.Sub(s.last).Seconds(); elapsed > 0 {
+		s.tokens = math.Min(burst, s.tokens+elapsed*s.Limit)
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+
+	s.tokens--
+	return true
+}
+
+// alwaysOnError checks if unsampled requests are logged on an error status.
+func (s *Sampler) alwaysOnError() bool {
+	return s != nil && s.AlwaysOnError
+}
+
+// isErrorStatus checks for a 4xx or 5xx status.
+func isErrorStatus(status int) bool {
+	return status >= http.StatusBadRequest
+}
+
+// hashFraction maps id to a fraction in [0, 1).
+func hashFraction(id string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+
+	// Mix the bits so IDs differing in their last characters spread evenly.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return float64(x>>11) / (1 << 53)
+}
@@ -0,0 +1,147 @@
+package logparams
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Sampling
+
+func TestSamplerIsDeterministic(t *testing.T) {
+	sampler := &Sampler{Rate: 0.25}
+
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-Id", fmt.Sprintf("req-%d", i))
+
+		first := sampler.Sample(req, time.Now())
+		if sampler.Sample(req, time.Now()) != first {
+			t.Fatalf("Expected request %d to be sampled the same way twice", i)
+		}
+		if first {
+			sampled++
+		}
+	}
+
+	if sampled < 2300 || sampled > 2700 {
+		t.Errorf("Expected about 2500 sampled requests, got %d", sampled)
+	}
+}
+
+func TestSamplerRequestIDHeader(t *testing.T) {
+	sampler := &Sampler{Rate: 0.5, RequestIDHeader: "X-Trace-Id"}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Trace-Id", sampledRequestID(false))
+
+	if sampler.Sample(req, time.Now()) {
+		t.Errorf("Expected request not to be sampled")
+	}
+}
+
+func TestSamplerRateLimit(t *testing.T) {
+	sampler := &Sampler{Limit: 1, Burst: 2}
+	req := httptest.NewRequest("GET", "/", nil)
+	start := time.Date(2020, 3, 22, 11, 15, 18, 0, time.UTC)
+
+	checks := []struct {
+		at       time.Duration
+		expected bool
+	}{
+		{0, true},
+		{0, true},
+		{0, false},
+		{500 * time.Millisecond, false},
+		{time.Second, true},
+		{time.Second, false},
+		{10 * time.Second, true},
+		{10 * time.Second, true},
+		{10 * time.Second, false},
+	}
+
+	for i, check := range checks {
+		if sampled := sampler.Sample(req, start.Add(check.at)); sampled != check.expected {
+			t.Errorf("Expected check %d to be %t, got %t", i, check.expected, sampled)
+		}
+	}
+}
+
+func TestNilSamplerSamplesEverything(t *testing.T) {
+	var sampler *Sampler
+	if !sampler.Sample(httptest.NewRequest("GET", "/", nil), time.Now()) {
+		t.Errorf("Expected nil sampler to sample every request")
+	}
+}
+
+func TestMiddlewareSamplerAlwaysOnError(t *testing.T) {
+	statuses := []struct {
+		status   int
+		expected string
+	}{
+		{http.StatusOK, "Completed 200 OK in 0ms\n"},
+		{http.StatusInternalServerError, "Parameters: {\"foo\" => \"bar\"}\nCompleted 500 Internal Server Error in 0ms\n"},
+	}
+
+	for _, s := range statuses {
+		var str bytes.Buffer
+		logger := log.New(&str, "", 0)
+
+		handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(s.status)
+		})
+
+		clock := func() time.Time { return time.Date(2020, 3, 22, 11, 15, 18, 0, time.UTC) }
+		lp := LogParams{Clock: clock, Sampler: &Sampler{Rate: 0.5, AlwaysOnError: true}}
+		req := httptest.NewRequest("GET", "/?foo=bar", nil)
+		req.Header.Set("X-Request-Id", sampledRequestID(false))
+		Middleware(logger, lp)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		if str.String() != s.expected {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), s.expected)
+		}
+	}
+}
+
+func TestRouteMiddlewareSamplerPerRoute(t *testing.T) {
+	var str bytes.Buffer
+	logger := log.New(&str, "", 0)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	routes := &Routes{
+		Rules:   []Route{{Prefix: "/hot", Params: LogParams{Sampler: &Sampler{Limit: 1}, HidePrefix: true}}},
+		Default: LogParams{HidePrefix: true},
+	}
+	middleware := RouteMiddleware(logger, routes)(handler)
+
+	for i := 0; i < 3; i++ {
+		middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", fmt.Sprintf("/hot?n=%d", i), nil))
+		middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", fmt.Sprintf("/cold?n=%d", i), nil))
+	}
+
+	expectedResults := []string{`{"n" => "0"}`, `{"n" => "0"}`, `{"n" => "1"}`, `{"n" => "2"}`}
+	var logged []string
+	for _, line := range bytes.Split(bytes.TrimSpace(str.Bytes()), []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("{")) {
+			logged = append(logged, string(line))
+		}
+	}
+
+	if fmt.Sprint(logged) != fmt.Sprint(expectedResults) {
+		t.Errorf("Expected lines were incorrect, got %v, want: %v", logged, expectedResults)
+	}
+}
+
+// sampledRequestID returns a request ID that is sampled, or not, at a rate of 0.5.
+func sampledRequestID(sampled bool) string {
+	for i := 0; ; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		if (hashFraction(id) < 0.5) == sampled {
+			return id
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package logparams
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sink receives the parsed parameters of requests, to route them to a dedicated
+// destination. Sinks must be safe for concurrent use.
+type Sink interface {
+	Log(entry Entry) error
+}
+
+// SinkFunc is a function used as a Sink.
+type SinkFunc func(entry Entry) error
+
+// Log calls f(entry).
+func (f SinkFunc) Log(entry Entry) error {
+	return f(entry)
+}
+
+// Entry is the parsed parameters of a request with the request metadata.
+type Entry struct {
+	Result
+	// Time is when the parameters were logged.
+	Time       time.Time
+	Method     string
+	URL        string
+	RemoteAddr string
+	// RequestID is the X-Request-Id header, or the RequestIDHeader of the Sampler.
+	RequestID string
+}
+
+// ToSink will send all parameters within the http request, with the request
+// metadata, to the sink.
+func (lp *LogParams) ToSink(sink Sink) error {
+	return lp.params().toSink(sink)
+}
+
+// toSink will send all parameters within the request, with the request metadata,
+// to the sink.
+func (lp *requestParams) toSink(sink Sink) error {
+	str, fields := lp.cachedParams()
+	if !lp.ShowEmpty && str == "" {
+		return nil
+	}
+
+	return sink.Log(lp.entry(str, fields))
+}
+
+// entry returns the Entry of the parameters.
+func (lp *requestParams) entry(str string, fields ParamFields) Entry {
+	header := DefaultRequestIDHeader
+	if lp.Sampler != nil && lp.Sampler.RequestIDHeader != "" {
+		header = lp.Sampler.RequestIDHeader
+	}
+
+	return Entry{
+		Result:     Result{String: str, Fields: fields},
+		Time:       lp.now(),
+		Method:     lp.Request.Method,
+		URL:        lp.Request.URL.RequestURI(),
+		RemoteAddr: lp.Request.RemoteAddr,
+		RequestID:  lp.Request.Header.Get(header),
+	}
+}
+
+// printParams will send the parameters to the Sink, or print them with logger if
+// it is not set.
+func (lp *requestParams) printParams(logger *log.Logger) {
+	if lp.Sink != nil {
+		lp.toSink(lp.Sink)
+		return
+	}
+
+	str, _ := lp.cachedParams()
+	logger.Print(str)
+}
+
+// NewLoggerSink returns a Sink printing the parameters with logger.
+func NewLoggerSink(logger *log.Logger) Sink {
+	return SinkFunc(func(entry Entry) error {
+		logger.Print(entry.String)
+		return nil
+	})
+}
+
+// writerSink writes the parameters as lines.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a Sink writing the parameters of each entry to w as a line.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// Log writes the entry.
+func (s *writerSink) Log(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := io.WriteString(s.w, entry.String+"\n")
+	return err
+}
+
+// slogSink logs structured records.
+type slogSink struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+// NewSlogSink returns a Sink logging each entry as a record at level with the
+// request metadata and a params group of the parsed fields:
+//
+//	level=INFO msg=Parameters method=POST url=/users request_id=f9b8c3a2 params.json=map[name:foo]
+func NewSlogSink(handler slog.Handler, level slog.Level) Sink {
+	return &slogSink{handler: handler, level: level}
+}
+
+// Log handles the entry as a record.
+func (s *slogSink) Log(entry Entry) error {
+	ctx := context.Background()
+	if !s.handler.Enabled(ctx, s.level) {
+		return nil
+	}
+
+	record := slog.NewRecord(entry.Time, s.level, "Parameters", 0)
+	record.AddAttrs(
+		slog.String("method", entry.Method),
+		slog.String("url", entry.URL),
+	)
+	if entry.RemoteAddr != "" {
+		record.AddAttrs(slog.String("remote_addr", entry.RemoteAddr))
+	}
+	if entry.RequestID != "" {
+		record.AddAttrs(slog.String("request_id", entry.RequestID))
+	}
+
+	var params []interface{}
+	fields := entry.Fields
+	for _, field := range []struct {
+		key   string
+		value map[string]string
+	}{
+		{"path", fields.Path},
+		{"form", fields.Form},
+		{"query", fields.Query},
+		{"headers", fields.Headers},
+		{"cookies", fields.Cookies},
+	} {
+		if len(field.value) != 0 {
+			params = append(params, slog.Any(field.key, field.value))
+		}
+	}
+	if len(fields.Json) != 0 {
+		params = append(params, slog.Any("json", fields.Json))
+	}
+	if len(fields.JsonArray) != 0 {
+		params = append(params, slog.Any("json_array", fields.JsonArray))
+	}
+	if len(params) != 0 {
+		record.AddAttrs(slog.Group("params", params...))
+	}
+
+	return s.handler.Handle(ctx, record)
+}
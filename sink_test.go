@@ -0,0 +1,169 @@
+package logparams
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// Sinks
+
+func TestToSinkEntry(t *testing.T) {
+	sink := NewRingBufferSink(10)
+	now := time.Date(2020, 3, 22, 11, 15, 18, 0, time.UTC)
+
+	req := httptest.NewRequest("GET", "/users?foo=bar", nil)
+	req.Header.Set("X-Request-Id", "abc123")
+	lp := LogParams{Request: req, Clock: func() time.Time { return now }}
+	if err := lp.ToSink(sink); err != nil {
+		t.Fatalf("Error logging to sink: %s", err)
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	expected := Entry{
+		Result:     Result{String: "Parameters: {\"foo\" => \"bar\"}", Fields: ParamFields{Query: map[string]string{"foo": "bar"}}},
+		Time:       now,
+		Method:     "GET",
+		URL:        "/users?foo=bar",
+		RemoteAddr: req.RemoteAddr,
+		RequestID:  "abc123",
+	}
+	if fmt.Sprint(entry) != fmt.Sprint(expected) {
+		t.Errorf("Expected entry was incorrect, got %+v, want: %+v", entry, expected)
+	}
+}
+
+func TestToSinkSkipsEmpty(t *testing.T) {
+	sink := NewRingBufferSink(10)
+	lp := LogParams{Request: httptest.NewRequest("GET", "/", nil)}
+	lp.ToSink(sink)
+
+	if len(sink.Entries()) != 0 {
+		t.Errorf("Expected no entries, got %d", len(sink.Entries()))
+	}
+}
+
+func TestRingBufferSinkKeepsLastEntries(t *testing.T) {
+	sink := NewRingBufferSink(2)
+	for _, str := range []string{"first", "second", "third"} {
+		sink.Log(testEntry(str))
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 2 || entries[0].String != "second" || entries[1].String != "third" {
+		t.Errorf("Expected the last 2 entries, got %+v", entries)
+	}
+}
+
+func TestWriterAndLoggerSinks(t *testing.T) {
+	var writer, logger bytes.Buffer
+	sinks := []Sink{NewWriterSink(&writer), NewLoggerSink(log.New(&logger, "INFO ", 0))}
+	for _, sink := range sinks {
+		sink.Log(testEntry("Parameters: {\"foo\" => \"bar\"}"))
+	}
+
+	if writer.String() != "Parameters: {\"foo\" => \"bar\"}\n" {
+		t.Errorf("Expected string was incorrect, got %s", writer.String())
+	}
+	if logger.String() != "INFO Parameters: {\"foo\" => \"bar\"}\n" {
+		t.Errorf("Expected string was incorrect, got %s", logger.String())
+	}
+}
+
+func TestSlogSink(t *testing.T) {
+	expectedResults := `{"level":"INFO","msg":"Parameters","method":"POST","url":"/users","request_id":"abc123","params":{"json":{"name":"foo","password":"[FILTERED]"}}}` + "\n"
+
+	var str bytes.Buffer
+	handler := slog.NewJSONHandler(&str, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"foo","password":"bar"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "abc123")
+	req.RemoteAddr = ""
+
+	lp := LogParams{Request: req}
+	lp.ToSink(NewSlogSink(handler, slog.LevelInfo))
+
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+
+	str.Reset()
+	lp.ToSink(NewSlogSink(handler, slog.LevelDebug))
+	if str.String() != "" {
+		t.Errorf("Expected disabled level not to be logged, got %s", str.String())
+	}
+}
+
+func TestRotatingFileSink(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "params.log")
+	sink := &RotatingFileSink{Filename: filename, MaxSize: 12, MaxBackups: 2}
+	defer sink.Close()
+
+	for i := 1; i <= 5; i++ {
+		if err := sink.Log(testEntry(fmt.Sprintf("entry %d", i))); err != nil {
+			t.Fatalf("Error logging to sink: %s", err)
+		}
+	}
+
+	expectedFiles := map[string]string{
+		"params.log":   "entry 5\n",
+		"params.log.1": "entry 4\n",
+		"params.log.2": "entry 3\n",
+	}
+	for name, expected := range expectedFiles {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Error reading %s: %s", name, err)
+		}
+		if string(b) != expected {
+			t.Errorf("Expected %s was incorrect, got %s, want: %s", name, b, expected)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "params.log.3")); !os.IsNotExist(err) {
+		t.Errorf("Expected params.log.3 not to be kept")
+	}
+}
+
+func TestMiddlewareSink(t *testing.T) {
+	expectedResults := regexp.MustCompile(`^Completed 200 OK in \d+ms\n$`)
+
+	var str bytes.Buffer
+	logger := log.New(&str, "", 0)
+	sink := NewRingBufferSink(10)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	Middleware(logger, LogParams{Sink: sink})(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?foo=bar", nil))
+
+	if !expectedResults.MatchString(str.String()) {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].String != "Parameters: {\"foo\" => \"bar\"}" {
+		t.Errorf("Expected parameters in sink, got %+v", entries)
+	}
+}
@@ -0,0 +1,301 @@
+package logparams
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods are the operations of an OpenAPI path item.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec holds the redaction rules of the operations in an OpenAPI 3 document, or of
+// every request for a JSON Schema.
+//
+// Query, path, form and JSON body parameters with `format: password` or
+// `x-sensitive: true` in their schema are masked. x-sensitive can also name the
+// redaction with the values of the log struct tag, e.g. `x-sensitive: hmac`.
+type Spec struct {
+	operations []specOperation
+	rules      []RedactRule
+}
+
+// specOperation is the redaction rules of an OpenAPI operation.
+type specOperation struct {
+	method  string
+	pattern *regexp.Regexp
+	rules   []RedactRule
+}
+
+// specParser resolves references while walking a document.
+type specParser struct {
+	root map[string]interface{}
+}
+
+// maxSpecDepth limits how deep schemas are walked, so recursive schemas end.
+const maxSpecDepth = 32
+
+// LoadSpec reads an OpenAPI 3 or JSON Schema document in JSON or YAML from the file.
+func LoadSpec(filename string) (*Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSpec(data)
+}
+
+// ParseSpec parses an OpenAPI 3 or JSON Schema document in JSON or YAML.
+func ParseSpec(data []byte) (*Spec, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, err
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("logparams: empty spec")
+	}
+
+	p := &specParser{root: root}
+	spec := &Spec{}
+	if _, ok := root["openapi"]; !ok {
+		spec.rules = p.schemaRules(root, "", 0)
+		return spec, nil
+	}
+
+	rootBases := serverBasePaths(root["servers"], []string{""})
+	paths, _ := root["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		pathItem := p.resolve(paths[path])
+		pathParams := p.parameterRules(pathItem["parameters"])
+		pathBases := serverBasePaths(pathItem["servers"], rootBases)
+		for _, method := range openAPIMethods {
+			operation := p.resolve(pathItem[method])
+			if operation == nil {
+				continue
+			}
+
+			rules := append([]RedactRule{}, pathParams...)
+			rules = append(rules, p.parameterRules(operation["parameters"])...)
+			rules = append(rules, p.requestBodyRules(operation["requestBody"])...)
+			if len(rules) == 0 {
+				continue
+			}
+
+			spec.operations = append(spec.operations, specOperation{
+				method:  strings.ToUpper(method),
+				pattern: pathPattern(serverBasePaths(operation["servers"], pathBases), path),
+				rules:   rules,
+			})
+		}
+	}
+
+	return spec, nil
+}
+
+// Rules returns the redaction rules of the operations matching the request.
+func (s *Spec) Rules(r *http.Request) []RedactRule {
+	if s == nil {
+		return nil
+	}
+
+	rules := s.rules
+	for _, operation := range s.operations {
+		if operation.method == r.Method && operation.pattern.MatchString(r.URL.Path) {
+			rules = append(rules[:len(rules):len(rules)], operation.rules...)
+		}
+	}
+
+	return rules
+}
+
+// parameterRules returns the rules of sensitive query and path parameters.
+func (p *specParser) parameterRules(v interface{}) []RedactRule {
+	params, _ := v.([]interface{})
+
+	var rules []RedactRule
+	for _, param := range params {
+		param := p.resolve(param)
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		if name == "" || (in != "query" && in != "path") {
+			continue
+		}
+
+		if redaction, ok := sensitive(param); ok {
+			rules = append(rules, RedactRule{Path: name, Redaction: redaction})
+		} else if redaction, ok := sensitive(p.resolve(param["schema"])); ok {
+			rules = append(rules, RedactRule{Path: name, Redaction: redaction})
+		}
+	}
+
+	return rules
+}
+
+// requestBodyRules returns the rules of the sensitive properties of the JSON and
+// form request body schemas.
+func (p *specParser) requestBodyRules(v interface{}) []RedactRule {
+	content, _ := p.resolve(v)["content"].(map[string]interface{})
+
+	var rules []RedactRule
+	for _, mediaType := range sortedKeys(content) {
+		if !strings.Contains(mediaType, "json") && !strings.Contains(mediaType, "form") {
+			continue
+		}
+
+		media, _ := content[mediaType].(map[string]interface{})
+		rules = append(rules, p.schemaRules(p.resolve(media["schema"]), "", 0)...)
+	}
+
+	return rules
+}
+
+// schemaRules returns the rules of the sensitive properties of the schema at path.
+// Array items share the path of their array.
+func (p *specParser) schemaRules(schema map[string]interface{}, path string, depth int) []RedactRule {
+	if schema == nil || depth > maxSpecDepth {
+		return nil
+	}
+
+	if path != "" {
+		if redaction, ok := sensitive(schema); ok {
+			return []RedactRule{{Path: path, Redaction: redaction}}
+		}
+	}
+
+	var rules []RedactRule
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(properties) {
+		rules = append(rules, p.schemaRules(p.resolve(properties[name]), jsonPath(path, name), depth+1)...)
+	}
+
+	rules = append(rules, p.schemaRules(p.resolve(schema["items"]), path, depth+1)...)
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		schemas, _ := schema[key].([]interface{})
+		for _, s := range schemas {
+			rules = append(rules, p.schemaRules(p.resolve(s), path, depth+1)...)
+		}
+	}
+
+	return rules
+}
+
+// resolve returns the object v, following a local $ref such as
+// "#/components/schemas/User".
+func (p *specParser) resolve(v interface{}) map[string]interface{} {
+	for i := 0; i < maxSpecDepth; i++ {
+		object, _ := v.(map[string]interface{})
+		ref, ok := object["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return object
+		}
+
+		v = p.pointer(ref[1:])
+	}
+
+	return nil
+}
+
+// pointer returns the value at the JSON pointer in the document.
+func (p *specParser) pointer(pointer string) interface{} {
+	var v interface{} = p.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = object[token]
+	}
+
+	return v
+}
+
+// sensitive returns the redaction of a schema or parameter marked with
+// format: password or x-sensitive.
+func sensitive(object map[string]interface{}) (Redaction, bool) {
+	switch marker := object["x-sensitive"].(type) {
+	case bool:
+		if marker {
+			return Mask, true
+		}
+	case string:
+		if redaction, ok := logTagRedactions[marker]; ok {
+			return redaction, true
+		}
+	}
+
+	if format, _ := object["format"].(string); format == "password" {
+		return Mask, true
+	}
+
+	return Mask, false
+}
+
+// serverBasePaths returns the base paths of the server URLs, e.g. "/v1" for
+// "https://api.example.com/v1", or bases if there are no servers.
+func serverBasePaths(v interface{}, bases []string) []string {
+	servers, _ := v.([]interface{})
+
+	var paths []string
+	for _, server := range servers {
+		server, _ := server.(map[string]interface{})
+		serverURL, _ := server["url"].(string)
+		if _, rest, ok := strings.Cut(serverURL, "://"); ok {
+			serverURL = ""
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				serverURL = rest[i:]
+			}
+		}
+		paths = append(paths, strings.TrimSuffix(serverURL, "/"))
+	}
+
+	if len(paths) == 0 {
+		return bases
+	}
+
+	return paths
+}
+
+// pathPattern returns a regular expression matching the OpenAPI path template,
+// e.g. "/users/{id}", under any of the base paths.
+func pathPattern(bases []string, path string) *regexp.Regexp {
+	patterns := make([]string, len(bases))
+	for i, base := range bases {
+		segments := strings.Split(base+path, "/")
+		for j, segment := range segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				segments[j] = "[^/]+"
+			} else {
+				segments[j] = regexp.QuoteMeta(segment)
+			}
+		}
+		patterns[i] = strings.Join(segments, "/")
+	}
+
+	return regexp.MustCompile("^(?:" + strings.Join(patterns, "|") + ")$")
+}
+
+// sortedKeys returns the keys of the object in order, so rules are built the same
+// way for every load.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,236 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// OpenAPI and JSON Schema specs
+
+const testOpenAPISpec = `
+openapi: 3.0.3
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        schema:
+          type: string
+    put:
+      parameters:
+        - name: token
+          in: query
+          x-sensitive: true
+        - name: page
+          in: query
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/User"
+  /reset/{token}:
+    post:
+      parameters:
+        - name: token
+          in: path
+          schema:
+            type: string
+            format: password
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        secret:
+          type: string
+          format: password
+        cards:
+          type: array
+          items:
+            type: object
+            properties:
+              number:
+                type: string
+                x-sensitive: partial
+`
+
+func TestParseSpecRules(t *testing.T) {
+	spec, err := ParseSpec([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	expectedRules := []RedactRule{
+		{Path: "token", Redaction: Mask},
+		{Path: "cards.number", Redaction: PartialMask},
+		{Path: "secret", Redaction: Mask},
+	}
+
+	req := httptest.NewRequest("PUT", "/users/42", nil)
+	if rules := spec.Rules(req); !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("Expected rules were incorrect, got %+v, want: %+v", rules, expectedRules)
+	}
+
+	req = httptest.NewRequest("GET", "/users/42", nil)
+	if rules := spec.Rules(req); len(rules) != 0 {
+		t.Errorf("Expected no rules for GET, got %+v", rules)
+	}
+
+	req = httptest.NewRequest("PUT", "/users/42/posts", nil)
+	if rules := spec.Rules(req); len(rules) != 0 {
+		t.Errorf("Expected no rules for unknown path, got %+v", rules)
+	}
+}
+
+func TestSpecJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"name\" => \"Jane\", \"secret\" => \"[FILTERED]\"}"
+
+	spec, err := ParseSpec([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Spec: spec}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"name":"Jane","secret":"hunter2"}`)
+	req, _ := http.NewRequest("PUT", server.URL+"/users/42", bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err = client.Do(req)
+	if err != nil {
+		t.Errorf("Error PUT to httptest server")
+	}
+}
+
+func TestSpecQueryToString(t *testing.T) {
+	expectedResults := "Parameters: {\"page\" => \"2\", \"token\" => \"[FILTERED]\"}"
+
+	spec, err := ParseSpec([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	req := httptest.NewRequest("PUT", "/users/42?token=abc&page=2", nil)
+	lp := LogParams{Request: req, Spec: spec}
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+}
+
+func TestSpecPathParamsToString(t *testing.T) {
+	expectedResults := "Parameters: {\"token\" => \"[FILTERED]\"}"
+
+	spec, err := ParseSpec([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	req := httptest.NewRequest("POST", "/reset/s3cret", nil)
+	req.SetPathValue("token", "s3cret")
+	lp := LogParams{Request: req, Spec: spec, PathParams: PathValues("token")}
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+}
+
+func TestSpecJSONBodyToFields(t *testing.T) {
+	spec, err := ParseSpec([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, Spec: spec}
+		cards := lp.ToFields().Json["cards"].([]interface{})
+		card := cards[0].(map[string]interface{})
+		if card["number"] != "************4242" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", card["number"], "************4242")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"cards":[{"number":"4242424242424242"}]}`)
+	req, _ := http.NewRequest("PUT", server.URL+"/users/42", bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err = client.Do(req)
+	if err != nil {
+		t.Errorf("Error PUT to httptest server")
+	}
+}
+
+func TestLoadJSONSchema(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schema.json")
+	schema := `{"type":"object","properties":{"user":{"type":"object","properties":{"pin":{"type":"string","x-sensitive":"omit"}}}}}`
+	if err := os.WriteFile(filename, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := LoadSpec(filename)
+	if err != nil {
+		t.Fatalf("Error loading spec: %s", err)
+	}
+
+	expectedRules := []RedactRule{{Path: "user.pin", Redaction: Remove}}
+	req := httptest.NewRequest("POST", "/anything", nil)
+	if rules := spec.Rules(req); !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("Expected rules were incorrect, got %+v, want: %+v", rules, expectedRules)
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	if _, err := ParseSpec([]byte("openapi: [")); err == nil {
+		t.Errorf("Expected error parsing invalid spec")
+	}
+}
+
+func TestSpecServerBasePathToString(t *testing.T) {
+	const serverSpec = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1/
+  - url: /{version}
+paths:
+  /login:
+    get:
+      parameters:
+        - name: token
+          in: query
+          x-sensitive: true
+`
+	spec, err := ParseSpec([]byte(serverSpec))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %s", err)
+	}
+
+	tests := map[string]string{
+		"/v1/login?token=abc": "Parameters: {\"token\" => \"[FILTERED]\"}",
+		"/v2/login?token=abc": "Parameters: {\"token\" => \"[FILTERED]\"}",
+		"/login?token=abc":    "Parameters: {\"token\" => \"abc\"}",
+	}
+	for target, expectedResults := range tests {
+		lp := LogParams{Request: httptest.NewRequest("GET", target, nil), Spec: spec}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect for %s, got %s, want: %s", target, lp.ToString(), expectedResults)
+		}
+	}
+}
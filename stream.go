@@ -0,0 +1,390 @@
+package logparams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// errUnexpectedJSON is returned when a streamed body is not a JSON object or array
+// of objects.
+var errUnexpectedJSON = errors.New("logparams: unexpected JSON token")
+
+// jsonStream renders a JSON body token by token, so only the logged part of the
+// body is held in memory.
+type jsonStream struct {
+	lp  *requestParams
+	dec *json.Decoder
+	buf *bytes.Buffer
+	// skipped holds each value read past, reusing its buffer.
+	skipped json.RawMessage
+}
+
+// replayBody replays the bytes read while logging, then the rest of the body.
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// streamJSONBody will render the JSON body with a streaming decoder, tee-ing the
+// bytes it reads so the body can be put back for the handler.
+func (lp *requestParams) streamJSONBody() (string, ParamFields) {
+	body := lp.Request.Body
+	if body == nil || body == http.NoBody {
+		return "", ParamFields{}
+	}
+
+	var read bytes.Buffer
+	defer func() {
+		lp.Request.Body = &replayBody{Reader: io.MultiReader(&read, body), Closer: body}
+	}()
+
+	reader, err := decompressReader(lp.Request.Header.Get("Content-Encoding"), io.TeeReader(body, &read), lp.maxDecompressedSize())
+	if err != nil {
+		return "", ParamFields{}
+	}
+	defer reader.Close()
+
+	var r io.Reader = reader
+	if decoder := lp.bodyDecoder(); decoder != nil {
+		r = decoder.Reader(r)
+	}
+
+	return lp.streamJSON(r)
+}
+
+// streamJSON will filter and render the json object or array of objects read from r.
+// Objects are rendered in the key order of the body.
+func (lp *requestParams) streamJSON(r io.Reader) (string, ParamFields) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	s := &jsonStream{lp: lp, dec: json.NewDecoder(r), buf: buf}
+	s.dec.UseNumber()
+	fields, err := s.body()
+	if err != nil {
+		return "", ParamFields{}
+	}
+
+	if _, err := s.dec.Token(); err != io.EOF {
+		return "", ParamFields{}
+	}
+
+	return buf.String(), fields
+}
+
+// body will render the top level object or array of objects.
+func (s *jsonStream) body() (ParamFields, error) {
+	token, err := s.dec.Token()
+	if err != nil {
+		return ParamFields{}, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		if !s.dec.More() {
+			_, err := s.dec.Token()
+			return ParamFields{}, err
+		}
+		object, err := s.object("", 1)
+		return ParamFields{Json: object}, err
+	case json.Delim('['):
+		var objects []map[string]interface{}
+		for s.dec.More() {
+			if len(objects) == 0 {
+				s.buf.WriteByte('[')
+			} else {
+				s.buf.WriteString(", ")
+			}
+
+			if token, err := s.dec.Token(); err != nil || token != json.Delim('{') {
+				return ParamFields{}, errUnexpectedJSON
+			}
+			object, err := s.object("", 1)
+			if err != nil {
+				return ParamFields{}, err
+			}
+			objects = append(objects, object)
+		}
+		if len(objects) != 0 {
+			s.buf.WriteByte(']')
+		}
+		_, err := s.dec.Token()
+		return ParamFields{JsonArray: objects}, err
+	}
+
+	return ParamFields{}, errUnexpectedJSON
+}
+
+// value will render the next value in the stream. key is the object key it belongs
+// to, path is its allowlist path and depth is its nesting depth. It returns false if
+// the value is left out, in which case nothing is written.
+func (s *jsonStream) value(key string, path string, depth int) (interface{}, bool, error) {
+	token, err := s.dec.Token()
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			if s.lp.MaxDepth > 0 && depth > s.lp.MaxDepth {
+				writeJSONString(s.buf, "…(truncated object)")
+				return "…(truncated object)", true, s.skipRest(value)
+			}
+			object, err := s.object(path, depth)
+			return object, true, err
+		}
+		if s.lp.MaxDepth > 0 && depth > s.lp.MaxDepth {
+			writeJSONString(s.buf, "…(truncated array)")
+			return "…(truncated array)", true, s.skipRest(value)
+		}
+		array, err := s.array(key, path, depth)
+		return array, true, err
+	case string:
+		filtered, ok := s.lp.writeFilteredValue(s.buf, key, path, value)
+		return filtered, ok, nil
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return nil, false, err
+		}
+		if !s.lp.allowed(path) {
+			filtered, ok := s.lp.writeFilteredValue(s.buf, key, path, jsonValueString(f))
+			return filtered, ok, nil
+		}
+		writeJSONNumber(s.buf, f)
+		return f, true, nil
+	case bool:
+		if !s.lp.allowed(path) {
+			filtered, ok := s.lp.writeFilteredValue(s.buf, key, path, strconv.FormatBool(value))
+			return filtered, ok, nil
+		}
+		writeJSONScalar(s.buf, value)
+		return value, true, nil
+	}
+
+	s.buf.WriteString("null")
+	return nil, true, nil
+}
+
+// streamEntry is the position of a rendered key and value in the buffer.
+type streamEntry struct {
+	key        string
+	start, end int
+}
+
+// object will render the rest of an object at path, after its opening brace. Keys
+// past MaxKeys are skipped, and the last value of a repeated key replaces the
+// earlier one, as encoding/json decodes it.
+func (s *jsonStream) object(path string, depth int) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	seen := make(map[string]bool)
+	var entries []streamEntry
+	repeated := false
+	dropped := make(map[string]bool)
+
+	s.buf.WriteByte('{')
+	open := s.buf.Len()
+	for s.dec.More() {
+		token, err := s.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		if !seen[key] && s.lp.MaxKeys > 0 && len(seen) >= s.lp.MaxKeys {
+			dropped[key] = true
+			if err := s.skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		repeated = repeated || seen[key]
+		seen[key] = true
+
+		mark := s.buf.Len()
+		if mark != open {
+			s.buf.WriteString(", ")
+		}
+		start := s.buf.Len()
+		writeJSONKey(s.buf, true, key)
+
+		var value interface{}
+		var ok bool
+		if redaction, redacted := s.lp.redactRule(key, jsonPath(path, key)); redacted {
+			value, ok, err = s.redacted(redaction)
+		} else {
+			value, ok, err = s.value(key, jsonPath(path, key), depth+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			object[key] = value
+			entries = append(entries, streamEntry{key: key, start: start, end: s.buf.Len()})
+		} else {
+			delete(object, key)
+			entries = append(entries, streamEntry{key: key, start: -1})
+			s.buf.Truncate(mark)
+		}
+	}
+	if repeated {
+		s.lastEntries(open, entries)
+	}
+
+	if len(dropped) > 0 {
+		writeJSONKey(s.buf, len(object) == 0, truncatedKey)
+		writeJSONString(s.buf, truncatedKeysValue(len(dropped)))
+		object[truncatedKey] = truncatedKeysValue(len(dropped))
+	}
+	s.buf.WriteByte('}')
+
+	_, err := s.dec.Token()
+	return object, err
+}
+
+// lastEntries will rewrite the entries of an object after open, with the last entry
+// of each key at the position of its first. A key whose last value was left out is
+// removed.
+func (s *jsonStream) lastEntries(open int, entries []streamEntry) {
+	last := make(map[string]streamEntry, len(entries))
+	for _, entry := range entries {
+		last[entry.key] = entry
+	}
+
+	rendered := getBuffer()
+	defer putBuffer(rendered)
+	rendered.Write(s.buf.Bytes()[open:])
+	s.buf.Truncate(open)
+
+	for _, entry := range entries {
+		entry, ok := last[entry.key]
+		if !ok || entry.start < 0 {
+			continue
+		}
+		delete(last, entry.key)
+
+		if s.buf.Len() != open {
+			s.buf.WriteString(", ")
+		}
+		s.buf.Write(rendered.Bytes()[entry.start-open : entry.end-open])
+	}
+}
+
+// array will render the rest of an array after its opening bracket. Elements past
+// MaxArrayElements are skipped.
+func (s *jsonStream) array(key string, path string, depth int) ([]interface{}, error) {
+	array := []interface{}{}
+	count := 0
+
+	s.buf.WriteByte('[')
+	for s.dec.More() {
+		count++
+		if s.lp.MaxArrayElements > 0 && count > s.lp.MaxArrayElements {
+			if err := s.skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		mark := s.buf.Len()
+		if len(array) != 0 {
+			s.buf.WriteString(", ")
+		}
+
+		element, ok, err := s.value(key, path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			array = append(array, element)
+		} else {
+			s.buf.Truncate(mark)
+		}
+	}
+
+	if dropped := count - s.lp.MaxArrayElements; s.lp.MaxArrayElements > 0 && dropped > 0 {
+		if len(array) != 0 {
+			s.buf.WriteString(", ")
+		}
+		writeJSONString(s.buf, truncatedElementsValue(dropped))
+		array = append(array, truncatedElementsValue(dropped))
+	}
+	s.buf.WriteByte(']')
+
+	_, err := s.dec.Token()
+	return array, err
+}
+
+// redacted will render the next value with the redaction. Values are only decoded
+// when the redaction needs them.
+func (s *jsonStream) redacted(redaction Redaction) (interface{}, bool, error) {
+	if redaction == PartialMask || redaction == HMAC {
+		var v interface{}
+		if err := s.dec.Decode(&v); err != nil {
+			return nil, false, err
+		}
+		if v == nil {
+			s.buf.WriteString("null")
+			return nil, true, nil
+		}
+		if number, ok := v.(json.Number); ok {
+			v, _ = number.Float64()
+		}
+
+		value := s.lp.redactValue(redaction, jsonValueString(v))
+		writeJSONString(s.buf, value)
+		return value, true, nil
+	}
+
+	token, err := s.dec.Token()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if delim, ok := token.(json.Delim); ok {
+		if err := s.skipRest(delim); err != nil {
+			return nil, false, err
+		}
+	} else if token == nil {
+		s.buf.WriteString("null")
+		return nil, true, nil
+	}
+
+	if redaction == Remove {
+		return nil, false, nil
+	}
+
+	value := s.lp.redactValue(redaction, "")
+	writeJSONString(s.buf, value)
+	return value, true, nil
+}
+
+// skip will read past the next value without rendering it.
+func (s *jsonStream) skip() error {
+	return s.dec.Decode(&s.skipped)
+}
+
+// skipRest will read past the rest of an object or array after its opening
+// delimiter.
+func (s *jsonStream) skipRest(delim json.Delim) error {
+	for s.dec.More() {
+		if delim == '{' {
+			if _, err := s.dec.Token(); err != nil {
+				return err
+			}
+		}
+		if err := s.skip(); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.dec.Token()
+	return err
+}
@@ -0,0 +1,154 @@
+package logparams
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Streaming JSON
+
+func TestStreamJSONMatchesDecodedJSON(t *testing.T) {
+	bodies := []string{
+		`{"active":true,"card":"4242424242424242","ids":[1,2.5,3,4],"note":null,"password":"secret","user":{"email":"foo@example.com","name":"foo","roles":["a","b","c"]}}`,
+		`[{"a":"b","n":1},{"a":"c","password":"x"}]`,
+		`{"a":{"b":{"c":{"d":"e"}}},"list":[[1,2],[3]]}`,
+		`{}`,
+		`[]`,
+		`"string"`,
+		`{"a":1} {"b":2}`,
+		`{"a":"1","b":"2","a":"3"}`,
+		`{"a":"1","c":{"x":"y"},"ids":[1],"password":"x","a":{"b":"c"},"ids":[2],"c":"d"}`,
+		`{"ids":[1],"z":"2","ids":"3"}`,
+	}
+	params := []LogParams{
+		{},
+		{MaxKeys: 2, MaxValueLength: 4},
+		{MaxDepth: 2, MaxArrayElements: 2},
+		{Redact: []RedactRule{{Key: "card", Redaction: PartialMask}, {Path: "user.email", Redaction: HMAC}, {Key: "ids", Redaction: Remove}}, HMACKey: []byte("key")},
+		{Allowlist: true, AllowKeys: []string{"user.name", "ids"}},
+	}
+
+	for _, body := range bodies {
+		for i, lp := range params {
+			lp.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+			lp.Request.Header.Set("Content-Type", "application/json")
+			expectedResults := lp.ToString()
+
+			lp.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+			lp.Request.Header.Set("Content-Type", "application/json")
+			lp.StreamJSON = true
+			if lp.ToString() != expectedResults {
+				t.Errorf("Expected string for params %d and body %s was incorrect, got %s, want: %s", i, body, lp.ToString(), expectedResults)
+			}
+		}
+	}
+}
+
+func TestStreamJSONKeepsKeyOrder(t *testing.T) {
+	expectedResults := "Parameters: {\"b\" => \"1\", \"a\" => \"2\"}"
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"b":"1","a":"2"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	lp := LogParams{Request: req, StreamJSON: true}
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+}
+
+func TestStreamJSONMaxArrayElementsToFields(t *testing.T) {
+	var ids []string
+	for i := 0; i < 10000; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	body := fmt.Sprintf(`{"ids":[%s]}`, strings.Join(ids, ","))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	lp := LogParams{Request: req, StreamJSON: true, MaxArrayElements: 3}
+	expectedResults := "Parameters: {\"ids\" => [0, 1, 2, \"…(truncated 9997 elements)\"]}"
+	if lp.ToString() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+	}
+
+	if elements := lp.ToFields().Json["ids"].([]interface{}); len(elements) != 4 {
+		t.Errorf("Expected 4 elements, got %d", len(elements))
+	}
+}
+
+func TestStreamJSONBodyIsLeftIntact(t *testing.T) {
+	bodies := []string{`{"foo":"bar"}`, `{"foo":`}
+
+	for _, body := range bodies {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		lp := LogParams{Request: req, StreamJSON: true}
+		lp.ToString()
+
+		result, _ := ioutil.ReadAll(req.Body)
+		if string(result) != body {
+			t.Errorf("Expected body was incorrect, got %s, want: %s", result, body)
+		}
+	}
+}
+
+func TestStreamCompressedJSONBody(t *testing.T) {
+	expectedResults := "Parameters: {\"foo\" => \"bar\"}"
+	body := compress([]byte(`{"foo":"bar"}`), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, StreamJSON: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+
+		result, _ := ioutil.ReadAll(r.Body)
+		if !bytes.Equal(result, body) {
+			t.Errorf("Expected body to be left compressed, got %v, want: %v", result, body)
+		}
+	}))
+
+	defer server.Close()
+
+	makeCompressedJSONRequest(server.URL, "gzip", body, t)
+}
+
+func TestStreamCompressedJSONBodyExceedsLimit(t *testing.T) {
+	body := compress([]byte(`{"foo":"bar"}`), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	lp := LogParams{Request: req, StreamJSON: true, MaxDecompressedSize: 8}
+	if lp.ToString() != "" {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), "")
+	}
+}
+
+func TestStreamJSONRepeatedKeysToFields(t *testing.T) {
+	body := `{"a":"1","b":"2","a":"3","ids":[1],"ids":"x"}`
+	params := []LogParams{{}, {Redact: []RedactRule{{Key: "ids", Redaction: Remove}}}}
+
+	for i, lp := range params {
+		lp.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+		lp.Request.Header.Set("Content-Type", "application/json")
+		expectedResults := lp.ToFields()
+
+		lp.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+		lp.Request.Header.Set("Content-Type", "application/json")
+		lp.StreamJSON = true
+		if fields := lp.ToFields(); fmt.Sprint(fields.Json) != fmt.Sprint(expectedResults.Json) {
+			t.Errorf("Expected fields for params %d were incorrect, got %v, want: %v", i, fields.Json, expectedResults.Json)
+		}
+	}
+}
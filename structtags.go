@@ -0,0 +1,150 @@
+package logparams
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structRules are the redaction rules and allowed paths derived from a struct type.
+type structRules struct {
+	redact []RedactRule
+	allow  []string
+}
+
+// structRulesCache caches the structRules of each struct type.
+var structRulesCache sync.Map
+
+// noStructRules are the rules without a struct type.
+var noStructRules = &structRules{}
+
+// logTagRedactions maps the values of the log struct tag to their redaction.
+var logTagRedactions = map[string]Redaction{
+	"redact":  Mask,
+	"mask":    Mask,
+	"partial": PartialMask,
+	"hmac":    HMAC,
+	"omit":    Remove,
+	"-":       Remove,
+}
+
+// StructRules returns the redaction rules and allowed paths defined by the log tags
+// of the struct type of v, which may be a struct value or pointer. JSON paths are
+// named by the json tags of the fields.
+//
+//	type PaymentRequest struct {
+//		Amount     int    `json:"amount" log:"allow"`
+//		CardNumber string `json:"card_number" log:"partial"`
+//		CVC        string `json:"cvc" log:"omit"`
+//		Email      string `json:"email" log:"hmac"`
+//		Name       string `json:"name" log:"redact"`
+//	}
+//
+// Tagging a field "allow" adds it to the allowed paths used in allowlist mode.
+func StructRules(v interface{}) ([]RedactRule, []string) {
+	rules := rulesForType(reflect.TypeOf(v))
+	return rules.redact, rules.allow
+}
+
+// structRules returns the rules of the BodyStruct.
+func (lp *requestParams) structRules() *structRules {
+	if lp.BodyStruct == nil {
+		return noStructRules
+	}
+
+	return rulesForType(reflect.TypeOf(lp.BodyStruct))
+}
+
+// rulesForType returns the cached rules of the struct type t.
+func rulesForType(t reflect.Type) *structRules {
+	if t == nil {
+		return noStructRules
+	}
+
+	if cached, ok := structRulesCache.Load(t); ok {
+		return cached.(*structRules)
+	}
+
+	rules := &structRules{}
+	collectStructRules(t, "", rules, map[reflect.Type]bool{})
+	structRulesCache.Store(t, rules)
+
+	return rules
+}
+
+// collectStructRules will add the rules of the fields of t at path to rules.
+func collectStructRules(t reflect.Type, path string, rules *structRules, seen map[reflect.Type]bool) {
+	t = elemType(t)
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened by encoding/json.
+		if name == "" {
+			collectStructRules(field.Type, path, rules, seen)
+			continue
+		}
+
+		fieldPath := jsonPath(path, name)
+		tag := strings.TrimSpace(field.Tag.Get("log"))
+		if redaction, ok := logTagRedactions[tag]; ok {
+			rules.redact = append(rules.redact, RedactRule{Path: fieldPath, Redaction: redaction})
+			continue
+		}
+
+		if tag == "allow" {
+			rules.allow = append(rules.allow, fieldPath)
+		}
+
+		collectStructRules(field.Type, fieldPath, rules, seen)
+	}
+}
+
+// jsonFieldName returns the JSON name of the field, an empty name for embedded
+// structs that are flattened, or false if the field is not encoded.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name != "" {
+		return name, true
+	}
+
+	if field.Anonymous && elemType(field.Type).Kind() == reflect.Struct {
+		return "", true
+	}
+
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	return field.Name, true
+}
+
+// elemType returns the element type of pointers, slices, arrays and maps.
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
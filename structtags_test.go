@@ -0,0 +1,110 @@
+package logparams
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// Struct tags
+
+type testAddress struct {
+	Street string `json:"street" log:"redact"`
+	City   string `json:"city" log:"allow"`
+}
+
+type testPaymentRequest struct {
+	Amount     string        `json:"amount" log:"allow"`
+	CardNumber string        `json:"card_number" log:"partial"`
+	CVC        string        `json:"cvc" log:"omit"`
+	Note       string        `json:"note"`
+	Billing    *testAddress  `json:"billing"`
+	Shipping   []testAddress `json:"shipping,omitempty"`
+	Internal   string        `json:"-" log:"redact"`
+}
+
+func TestStructRules(t *testing.T) {
+	expectedRules := []RedactRule{
+		{Path: "card_number", Redaction: PartialMask},
+		{Path: "cvc", Redaction: Remove},
+		{Path: "billing.street", Redaction: Mask},
+		{Path: "shipping.street", Redaction: Mask},
+	}
+	expectedAllow := []string{"amount", "billing.city", "shipping.city"}
+
+	rules, allow := StructRules(&testPaymentRequest{})
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("Expected rules were incorrect, got %+v, want: %+v", rules, expectedRules)
+	}
+	if !reflect.DeepEqual(allow, expectedAllow) {
+		t.Errorf("Expected allowed paths were incorrect, got %v, want: %v", allow, expectedAllow)
+	}
+}
+
+func TestBodyStructJSONBodyToString(t *testing.T) {
+	expectedResults := "Parameters: {\"amount\" => \"10.00\", \"card_number\" => \"************4242\", \"note\" => \"[FILTERED]\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, BodyStruct: testPaymentRequest{}, Allowlist: true}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"amount":"10.00","card_number":"4242424242424242","cvc":"123","note":"hi"}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+type testCardRequest struct {
+	CardNumber string `json:"card_number" log:"redact"`
+	CVC        string `log:"omit"`
+	Billing    testAddress
+}
+
+func TestBodyStructMatchesKeysIgnoringCase(t *testing.T) {
+	body := `{"Card_Number":"4242424242424242","cvc":"123","BILLING":{"STREET":"1 Main St"}}`
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	lp := LogParams{Request: req, BodyStruct: testCardRequest{}}
+
+	expectedResults := "Parameters: {\"BILLING\" => {\"STREET\" => \"[FILTERED]\"}, \"Card_Number\" => \"[FILTERED]\"}"
+	if result := lp.ToString(); result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+}
+
+func TestBodyStructJSONBodyToField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, BodyStruct: &testPaymentRequest{}}
+		billing := lp.ToFields().Json["billing"].(map[string]interface{})
+		if billing["street"] != "[FILTERED]" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", billing["street"], "[FILTERED]")
+		}
+		if billing["city"] != "Portland" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", billing["city"], "Portland")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"billing":{"street":"1 Main St","city":"Portland"}}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
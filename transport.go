@@ -0,0 +1,175 @@
+package logparams
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+)
+
+// DefaultMaxTransportBodySize is the outbound body buffering limit used when
+// MaxBodySize is not set.
+const DefaultMaxTransportBodySize = 10 << 20 // 10MB
+
+// discardLogger drops the lines of a Transport without a Logger.
+var discardLogger = log.New(ioutil.Discard, "", 0)
+
+// Transport is a http.RoundTripper that logs the parameters of outbound requests
+// with the same filtering as incoming requests, and optionally the responses.
+//
+//	client := &http.Client{Transport: &logparams.Transport{Logger: logger}}
+type Transport struct {
+	// Base is the RoundTripper used to send the request (default http.DefaultTransport).
+	Base http.RoundTripper
+	// Logger receives the log lines, which are dropped if it is nil. The parameters
+	// go to the Sink of Params if it is set.
+	Logger *log.Logger
+	// Params is used as the configuration for every request, its Request is ignored.
+	Params LogParams
+	// LogResponse will log the Completed line, and the JSON response body when
+	// Params.ShowResponseBody is set.
+	LogResponse bool
+	// MaxBodySize limits the size of a JSON or form body buffered to be logged when
+	// the request has no GetBody (default 10MB). Larger bodies are sent without
+	// being logged.
+	MaxBodySize int64
+}
+
+// RoundTrip logs the request parameters and sends the request with the Base RoundTripper.
+// The request body is rewound with GetBody so it can still be sent and retried.
+// Bodies without GetBody are only buffered when they are JSON or form bodies within
+// MaxBodySize, other bodies are sent without being logged.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	config := t.Params.config()
+	start := config.now()
+	logger := t.logger()
+
+	req, loggable, err := t.rewindableRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	logReq := req.Clone(req.Context())
+	logReq.Body = http.NoBody
+	if loggable && req.GetBody != nil {
+		logReq.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := config.params(logReq)
+	if params.ShowLifecycle {
+		logger.Printf("Started %s \"%s\" at %s", req.Method, params.filteredURL(req.URL).Redacted(), start.Format("2006-01-02 15:04:05 -0700"))
+	}
+	if str := params.toString(); str != "" || params.ShowEmpty {
+		params.printParams(logger)
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil || !t.LogResponse {
+		return resp, err
+	}
+
+	logger.Print(completedString(resp.StatusCode, config.now().Sub(start)))
+	if params.ShowResponseBody && isJSONContentType(resp.Header.Get("Content-Type")) {
+		if response := params.responseBodyString(resp); response != "" {
+			logger.Print(response)
+		}
+	}
+
+	return resp, nil
+}
+
+// base returns the Base RoundTripper, or http.DefaultTransport if it is not set.
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+// logger returns the Logger, or a logger dropping the lines if it is not set.
+func (t *Transport) logger() *log.Logger {
+	if t.Logger != nil {
+		return t.Logger
+	}
+
+	return discardLogger
+}
+
+// maxBodySize returns MaxBodySize, or DefaultMaxTransportBodySize if it is not set.
+func (t *Transport) maxBodySize() int64 {
+	if t.MaxBodySize > 0 {
+		return t.MaxBodySize
+	}
+
+	return DefaultMaxTransportBodySize
+}
+
+// rewindableRequest returns req if its body can be read again with GetBody. A JSON
+// or form body without GetBody is buffered up to MaxBodySize, returning a copy of
+// req with GetBody set. It returns false if the body can't be logged.
+func (t *Transport) rewindableRequest(req *http.Request) (*http.Request, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, true, nil
+	}
+
+	if !isLoggableBody(req.Header.Get("Content-Type")) {
+		return req, false, nil
+	}
+
+	limit := t.maxBodySize()
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		req.Body.Close()
+		return nil, false, err
+	}
+
+	if int64(len(body)) > limit {
+		// Send the bytes read followed by the rest of the body.
+		large := req.Clone(req.Context())
+		large.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		return large, false, nil
+	}
+	req.Body.Close()
+
+	rewindable := req.Clone(req.Context())
+	rewindable.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	rewindable.Body, _ = rewindable.GetBody()
+	rewindable.ContentLength = int64(len(body))
+
+	return rewindable, true, nil
+}
+
+// isLoggableBody checks if a body of the content type is logged, for JSON and url
+// encoded form bodies.
+func isLoggableBody(contentType string) bool {
+	if isJSONContentType(contentType) {
+		return true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/x-www-form-urlencoded"
+}
+
+// responseBodyString will read up to MaxResponseBodySize bytes of the response body
+// and return a string of it filtered, putting the bytes back for the caller.
+func (lp *requestParams) responseBodyString(resp *http.Response) string {
+	limit := lp.maxResponseBodySize()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil || len(body) > limit {
+		return ""
+	}
+
+	return lp.renderResponseBody(resp.Header.Get("Content-Encoding"), body)
+}
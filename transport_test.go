@@ -0,0 +1,220 @@
+package logparams
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Transport
+
+func TestTransportFormToLogger(t *testing.T) {
+	expectedResults := "Parameters: {\"password\" => \"[FILTERED]\"}"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("password") != "foo" {
+			t.Errorf("Expected attribute was incorrect, got %s, want: %s", r.PostFormValue("password"), "foo")
+		}
+	}))
+
+	defer server.Close()
+
+	params := url.Values{}
+	params.Set("password", "foo")
+
+	client := &http.Client{Transport: &Transport{Logger: &logger}}
+	_, err := client.PostForm(server.URL, params)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+
+	result := strings.TrimSuffix(str.String(), "\n")
+	if result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+}
+
+func TestTransportBodyCanBeRetried(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	var sent *http.Request
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+
+	// The body is wrapped so http.NewRequest can not set GetBody.
+	req, _ := http.NewRequest("POST", "http://example.com", ioutil.NopCloser(strings.NewReader(`{"foo":"bar"}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	transport := &Transport{Base: base, Logger: &logger}
+	_, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Errorf("Error sending request: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		body, _ := sent.GetBody()
+		b, _ := ioutil.ReadAll(body)
+		if string(b) != `{"foo":"bar"}` {
+			t.Errorf("Expected body was incorrect, got %s, want: %s", b, `{"foo":"bar"}`)
+		}
+	}
+
+	b, _ := ioutil.ReadAll(sent.Body)
+	if string(b) != `{"foo":"bar"}` {
+		t.Errorf("Expected body was incorrect, got %s, want: %s", b, `{"foo":"bar"}`)
+	}
+}
+
+func TestTransportResponseToLogger(t *testing.T) {
+	expectedResults := "Response: {\"token\" => \"abc\"}"
+
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"token":"abc"}`)
+	}))
+
+	defer server.Close()
+
+	transport := &Transport{Logger: &logger, Params: LogParams{ShowResponseBody: true}, LogResponse: true}
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL + "?foo=bar")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `{"token":"abc"}` {
+		t.Errorf("Expected body was incorrect, got %s, want: %s", body, `{"token":"abc"}`)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(str.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "Parameters: {\"foo\" => \"bar\"}" || !strings.HasPrefix(lines[1], "Completed 200 OK in ") || lines[2] != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestTransportUnloggableBodyIsNotBuffered(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	upload := strings.Repeat("x", 1<<20)
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.GetBody != nil {
+			t.Errorf("Expected body not to be buffered")
+		}
+		if b, _ := ioutil.ReadAll(req.Body); string(b) != upload {
+			t.Errorf("Expected body was incorrect, got %d bytes, want: %d", len(b), len(upload))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+
+	req, _ := http.NewRequest("PUT", "http://example.com/upload?name=foo", ioutil.NopCloser(strings.NewReader(upload)))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	transport := &Transport{Base: base, Logger: &logger}
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Errorf("Error sending request: %s", err)
+	}
+
+	expectedResults := "Parameters: {\"name\" => \"foo\"}\n"
+	if str.String() != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
+
+func TestTransportBodyOverMaxBodySizeIsNotLogged(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	body := `{"foo":"bar","baz":"qux"}`
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if b, _ := ioutil.ReadAll(req.Body); string(b) != body {
+			t.Errorf("Expected body was incorrect, got %s, want: %s", b, body)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+
+	req, _ := http.NewRequest("POST", "http://example.com", ioutil.NopCloser(strings.NewReader(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	transport := &Transport{Base: base, Logger: &logger, MaxBodySize: 10}
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Errorf("Error sending request: %s", err)
+	}
+
+	if str.String() != "" {
+		t.Errorf("Expected no parameters to be logged, got %s", str.String())
+	}
+}
+
+func TestTransportWithoutLogger(t *testing.T) {
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+
+	sink := NewRingBufferSink(10)
+	transport := &Transport{
+		Base:        base,
+		Params:      LogParams{Sink: sink, ShowLifecycle: true},
+		LogResponse: true,
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com?foo=bar", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Errorf("Error sending request: %s", err)
+	}
+
+	expected := "Parameters: {\"foo\" => \"bar\"}"
+	if entries := sink.Entries(); len(entries) != 1 || entries[0].String != expected {
+		t.Errorf("Expected entries were incorrect, got %v, want: %s", entries, expected)
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportLifecycleFiltersURL(t *testing.T) {
+	var str bytes.Buffer
+	var logger = log.Logger{}
+	logger.SetOutput(&str)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	defer server.Close()
+
+	serverURL := strings.Replace(server.URL, "http://", "http://bob:s3cret@", 1)
+	expectedResults := fmt.Sprintf("Started GET \"%s/x?password=[FILTERED]\" at ", strings.Replace(server.URL, "http://", "http://bob:xxxxx@", 1))
+
+	client := &http.Client{Transport: &Transport{Logger: &logger, Params: LogParams{ShowLifecycle: true}}}
+	_, err := client.Get(serverURL + "/x?password=hunter2")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+
+	if !strings.HasPrefix(str.String(), expectedResults) || strings.Contains(str.String(), "hunter2") || strings.Contains(str.String(), "s3cret") {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", str.String(), expectedResults)
+	}
+}
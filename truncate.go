@@ -0,0 +1,96 @@
+package logparams
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// truncatedKey is the key holding the number of keys left out by MaxKeys.
+const truncatedKey = "…"
+
+// truncateValue will cut s to MaxValueLength bytes and mark how much was left out.
+func (lp *requestParams) truncateValue(s string) string {
+	return truncateString(s, lp.MaxValueLength)
+}
+
+// truncateOutput will cut s to MaxOutputSize bytes and mark how much was left out.
+func (lp *requestParams) truncateOutput(s string) string {
+	return truncateString(s, lp.MaxOutputSize)
+}
+
+// formatOutput will format the result with the Formatter, leaving out the cookies,
+// headers, path and then the parameters until it fits in MaxOutputSize. The output
+// is not cut, so it stays valid, and only a marker is left if nothing fits.
+func (lp *requestParams) formatOutput(result Result) string {
+	str := lp.Formatter.Format(result)
+	if lp.MaxOutputSize <= 0 || len(str) <= lp.MaxOutputSize {
+		return str
+	}
+	size := len(str)
+
+	fields := &result.Fields
+	for _, drop := range []func(){
+		func() { fields.Cookies = nil },
+		func() { fields.Headers = nil },
+		func() { fields.Path = nil },
+		func() { fields.JsonArray = nil },
+		func() { fields.Json = nil },
+		func() { fields.Query = nil },
+		func() { fields.Form = nil },
+	} {
+		drop()
+		if str = lp.Formatter.Format(result); len(str) <= lp.MaxOutputSize {
+			return str
+		}
+	}
+
+	return fmt.Sprintf("…(truncated %d bytes)", size)
+}
+
+// truncateString will cut s to max bytes, without splitting a UTF-8 sequence, and
+// append a marker with the number of bytes left out. A max of 0 is unlimited.
+func truncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s…(truncated %d bytes)", s[:cut], len(s)-cut)
+}
+
+// truncateKeys will sort the keys and keep the first MaxKeys of them, returning
+// the number of keys left out.
+func (lp *requestParams) truncateKeys(keys []string) ([]string, int) {
+	sort.Strings(keys)
+	if lp.MaxKeys <= 0 || len(keys) <= lp.MaxKeys {
+		return keys, 0
+	}
+
+	return keys[:lp.MaxKeys], len(keys) - lp.MaxKeys
+}
+
+// truncatedKeysValue returns the marker value for the number of keys left out.
+func truncatedKeysValue(dropped int) string {
+	return fmt.Sprintf("(truncated %d keys)", dropped)
+}
+
+// truncatedElementsValue returns the marker element for the number of array
+// elements left out.
+func truncatedElementsValue(dropped int) string {
+	return fmt.Sprintf("…(truncated %d elements)", dropped)
+}
+
+// valueKeys returns the keys of the form or query values.
+func valueKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
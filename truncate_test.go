@@ -0,0 +1,157 @@
+package logparams
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Truncation
+
+func TestMaxValueLengthFormToString(t *testing.T) {
+	expectedResults := "Parameters: {\"bio\" => \"abcd…(truncated 6 bytes)\", \"name\" => \"日…(truncated 3 bytes)\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, MaxValueLength: 4}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Form["bio"] != "abcd…(truncated 6 bytes)" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Form["bio"], "abcd…(truncated 6 bytes)")
+		}
+	}))
+
+	defer server.Close()
+
+	makeFormRequest(server.URL, "POST", "bio=abcdefghij&name=日本", t)
+}
+
+func TestMaxKeysQueryToString(t *testing.T) {
+	expectedResults := "Parameters: {\"a\" => \"1\", \"b\" => \"2\", \"…\" => \"(truncated 2 keys)\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, MaxKeys: 2}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?d=4&c=3&b=2&a=1")
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestJSONLimitsToString(t *testing.T) {
+	expectedResults := "Parameters: {\"a\" => \"xy…(truncated 3 bytes)\", \"b\" => \"…(truncated object)\", \"…\" => \"(truncated 1 keys)\"}"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, MaxValueLength: 2, MaxKeys: 2, MaxDepth: 1}
+		if lp.ToString() != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToString(), expectedResults)
+		}
+		if lp.ToFields().Json["b"] != "…(truncated object)" {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", lp.ToFields().Json["b"], "…(truncated object)")
+		}
+	}))
+
+	defer server.Close()
+
+	var jsonStr = []byte(`{"a":"xyzzy","b":{"c":"d"},"c":"e"}`)
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBuffer(jsonStr))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	_, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error POST to httptest server")
+	}
+}
+
+func TestMaxOutputSizeToString(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lp := LogParams{Request: r, MaxOutputSize: 20}
+		result := lp.ToString()
+		expectedResults := "Parameters: {\"foo\" =…(truncated 1005 bytes)"
+		if result != expectedResults {
+			t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+		}
+	}))
+
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "?foo=" + strings.Repeat("a", 1000))
+	if err != nil {
+		t.Errorf("Error GET to httptest server")
+	}
+}
+
+func TestMaxOutputSizeKeepsFormatterOutputValid(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?foo="+strings.Repeat("a", 100), nil)
+	lp := LogParams{Request: req, MaxOutputSize: 20, Formatter: JSONFormatter}
+
+	result := lp.ToString()
+	if !json.Valid([]byte(result)) {
+		t.Errorf("Expected valid JSON, got %s", result)
+	}
+}
+
+func TestMaxValueLengthHeadersCookiesAndPathToString(t *testing.T) {
+	req := httptest.NewRequest("GET", "/users/abcdefgh", nil)
+	req.Header.Set("User-Agent", "curl/8.4.0 (x86_64)")
+	req.Header.Set("Authorization", "Bearer abcdefgh")
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "solarized"})
+	lp := LogParams{
+		Request:        req,
+		MaxValueLength: 4,
+		LogHeaders:     []string{"User-Agent", "Authorization"},
+		ShowCookies:    true,
+		PathParams: func(r *http.Request) map[string]string {
+			return map[string]string{"id": "abcdefgh"}
+		},
+	}
+
+	expectedResults := `Parameters: {"id" => "abcd…(truncated 4 bytes)"} ` +
+		`Headers: {"Authorization" => "[FILTERED]", "User-Agent" => "curl…(truncated 15 bytes)"} ` +
+		`Cookies: {"theme" => "sola…(truncated 5 bytes)"}`
+	if result := lp.ToString(); result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+}
+
+func TestMaxOutputSizeLeavesOutFormatterFields(t *testing.T) {
+	expectedResults := `{"query":{"foo":"bar"}}`
+
+	req := httptest.NewRequest("GET", "/?foo=bar", nil)
+	req.Header.Set("User-Agent", strings.Repeat("a", 100))
+	lp := LogParams{Request: req, MaxOutputSize: 30, LogHeaders: []string{"User-Agent"}, Formatter: JSONFormatter}
+
+	result := lp.ToString()
+	if result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+	if lp.ToFields().Headers["User-Agent"] == "" {
+		t.Errorf("Expected fields to keep the headers")
+	}
+}
+
+func TestMaxOutputSizeFormatterMarker(t *testing.T) {
+	expectedResults := "…(truncated 100 bytes)"
+
+	req := httptest.NewRequest("GET", "/?foo=bar", nil)
+	lp := LogParams{
+		Request:       req,
+		MaxOutputSize: 30,
+		Formatter:     FormatterFunc(func(result Result) string { return strings.Repeat("a", 100) }),
+	}
+
+	result := lp.ToString()
+	if result != expectedResults {
+		t.Errorf("Expected string was incorrect, got %s, want: %s", result, expectedResults)
+	}
+}